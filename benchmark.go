@@ -34,8 +34,6 @@ import (
 	"time"
 )
 
-var memStats runtime.MemStats
-
 // testingB is a type passed to Benchmark functions to manage benchmark
 // timing and to specify the number of iterations to run.
 type timer struct {
@@ -57,6 +55,7 @@ type timer struct {
 // before a benchmark starts, but it can also used to resume timing after
 // a call to StopTimer.
 func (c *C) StartTimer() {
+	var memStats runtime.MemStats
 	if !c.timerOn {
 		c.start = time.Now()
 		c.timerOn = true
@@ -71,6 +70,7 @@ func (c *C) StartTimer() {
 // while performing complex initialization that you don't
 // want to measure.
 func (c *C) StopTimer() {
+	var memStats runtime.MemStats
 	if c.timerOn {
 		c.duration += time.Now().Sub(c.start)
 		c.timerOn = false
@@ -83,6 +83,7 @@ func (c *C) StopTimer() {
 // ResetTimer sets the elapsed benchmark time to zero.
 // It does not affect whether the timer is running.
 func (c *C) ResetTimer() {
+	var memStats runtime.MemStats
 	if c.timerOn {
 		c.start = time.Now()
 		runtime.ReadMemStats(&memStats)
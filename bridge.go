@@ -0,0 +1,94 @@
+package check
+
+import (
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------
+// Bridge to the subtests of the "testing" package, for TestingSubtests.
+
+// Run the test forked by fork as a subtest of the suite's testing.T, and
+// report its outcome to the subtest once it's done. Returns the call,
+// or nil if the subtest was left out by -test.run. A test paused after
+// calling C.Parallel pauses the subtest as well, and is reported once
+// the suite resumes it.
+func (runner *suiteRunner) runTestT(method *methodType, fork func(*methodType, *testing.T) *C) *C {
+	var c *C
+	runner.testingT.Run(method.Info.Name, func(t *testing.T) {
+		c = fork(method, t)
+		select {
+		case <-c.done:
+		case <-c.paused:
+			t.Parallel()
+			<-c.done
+		}
+		reportT(t, c)
+	})
+	return c
+}
+
+// Run f as a subtest of the parent call and of its testing.T, and report
+// its outcome to the latter once it's done. Returns the call, or nil if
+// the subtest was left out by -test.run.
+func (runner *suiteRunner) runSubtestT(parent *C, name string, f func(c *C)) *C {
+	var c *C
+	parent.t.Run(name, func(t *testing.T) {
+		c = runner.forkSubtest(parent, name, f, t)
+		<-c.done
+		reportT(t, c)
+	})
+	return c
+}
+
+// Report the outcome of a finished test call to its testing.T. The call
+// log is taken from its result, since the console output consumes it.
+func reportT(t *testing.T, c *C) {
+	result := c.result
+	log := strings.TrimSuffix(result.Log, "\n")
+	if result.Retried {
+		t.Skip(strings.TrimPrefix(log+"\nFailed, running it again", "\n"))
+	}
+	switch result.Status {
+	case EventPass, EventExpectedFailure:
+		if result.Benchmark != nil {
+			t.Log(c.timerString())
+		}
+	case EventSkip:
+		t.Skip(result.Reason)
+	case EventMiss:
+		missed := "Missed"
+		if result.Reason != "" {
+			missed += " (" + result.Reason + ")"
+		}
+		t.Error(strings.TrimPrefix(log+"\n"+missed, "\n"))
+	default:
+		if log != "" {
+			t.Error(log)
+		} else {
+			t.Fail()
+		}
+	}
+}
+
+// testingReporter reports the problems with fixture calls, which are not
+// part of any one test, as failures of the suite's testing.T.
+type testingReporter struct {
+	t *testing.T
+}
+
+func (r testingReporter) Report(e event, c *C) {
+	if c.kind != fixtureKd {
+		return
+	}
+	var label string
+	switch e {
+	case failure:
+		label = "FAIL"
+	case panicked:
+		label = "PANIC"
+	default:
+		return
+	}
+	r.t.Error(renderCallHeader(label, c, "", "\n\n") + strings.TrimSuffix(c.logb.String(), "\n"))
+}
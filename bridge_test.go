@@ -0,0 +1,107 @@
+package check_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	. "github.com/elopio/check"
+)
+
+var _ = Suite(&bridgeS{})
+
+type bridgeS struct{}
+
+// The helper suites below are run with TestingSubtests by the test binary
+// itself, in a separate process, since their failures would otherwise
+// fail this test run.
+func TestBridgeHelper(t *testing.T) {
+	if os.Getenv("CHECK_BRIDGE_HELPER") == "" {
+		t.Skip("Only run from bridgeS")
+	}
+	TestingSubtestsOf(t, []interface{}{
+		&SuccessHelper{},
+		&FailHelper{},
+		&FixtureHelper{panicOn: "SetUpTest"},
+		&SkipTestHelper{},
+		&ParallelHelper{},
+		&SubtestHelper{},
+	})
+}
+
+func runBridgeHelper(c *C, args ...string) string {
+	state := filepath.Join(c.MkDir(), "state")
+	args = append([]string{"-test.run=^TestBridgeHelper$", "-test.v", "-check.parallel=2", "-check.state=" + state}, args...)
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "CHECK_BRIDGE_HELPER=1")
+	output, err := cmd.CombinedOutput()
+	c.Assert(err, NotNil)
+	return string(output)
+}
+
+func (s *bridgeS) TestSubtests(c *C) {
+	output := runBridgeHelper(c)
+	for _, line := range []string{
+		"--- PASS: TestBridgeHelper/SuccessHelper/TestLogAndSucceed",
+		"--- FAIL: TestBridgeHelper/FailHelper/TestLogAndFail",
+		"--- FAIL: TestBridgeHelper/FixtureHelper",
+		"--- FAIL: TestBridgeHelper/FixtureHelper/Test1",
+		"--- FAIL: TestBridgeHelper/FixtureHelper/Test2",
+		"--- SKIP: TestBridgeHelper/SkipTestHelper/TestFail",
+		"--- PASS: TestBridgeHelper/ParallelHelper/Test1",
+		"--- PASS: TestBridgeHelper/ParallelHelper/Test2",
+	} {
+		c.Check(output, Matches, "(?s).*\n *"+regexp.QuoteMeta(line)+" \\([.0-9]+s\\)\n.*")
+	}
+	for _, log := range []string{
+		"Expected failure!",
+		"PANIC: check_test\\.go:[0-9]+: FixtureHelper\\.SetUpTest\n(.*\n)* *\\.\\.\\. Panic: SetUpTest ",
+		"\\.\\.\\. Panic: Fixture has panicked \\(see related PANIC\\)",
+		"Missed",
+		"Wrong platform or whatever",
+	} {
+		c.Check(output, Matches, "(?s).*\n *bridge\\.go:[0-9]+: "+log+".*")
+	}
+}
+
+func (s *bridgeS) TestSubtestsOfTests(c *C) {
+	output := runBridgeHelper(c, "-test.run=^TestBridgeHelper$/^SubtestHelper$")
+	for _, line := range []string{
+		"--- PASS: TestBridgeHelper/SubtestHelper/TestNested/outer/inner",
+		"--- FAIL: TestBridgeHelper/SubtestHelper/TestTable",
+		"--- PASS: TestBridgeHelper/SubtestHelper/TestTable/pass",
+		"--- FAIL: TestBridgeHelper/SubtestHelper/TestTable/fail",
+		"--- SKIP: TestBridgeHelper/SubtestHelper/TestTable/skip_me",
+	} {
+		c.Check(output, Matches, "(?s).*\n *"+regexp.QuoteMeta(line)+" \\([.0-9]+s\\)\n.*")
+	}
+	c.Check(output, Matches, "(?s).*\n=== RUN +TestBridgeHelper/SubtestHelper/TestTable/fail\n"+
+		" *bridge\\.go:[0-9]+: Expected failure!\n.*")
+}
+
+func (s *bridgeS) TestSubtestsFlags(c *C) {
+	junit := filepath.Join(c.MkDir(), "junit.xml")
+	output := runBridgeHelper(c, "-check.failfast", "-check.junit="+junit)
+	c.Check(output, Matches, "(?s).*\n *--- FAIL: TestBridgeHelper/FailHelper/TestLogAndFail .*")
+	c.Check(output, Matches, "(?s).*\n=== RUN +TestBridgeHelper/FixtureHelper/Test1\n *bridge\\.go:[0-9]+: Missed \\(not run\\)\n.*")
+	c.Check(output, Not(Matches), "(?s).*Panic: SetUpTest.*")
+	report, err := ioutil.ReadFile(junit)
+	c.Assert(err, IsNil)
+	c.Check(string(report), Matches, "(?s).*<testsuite name=\"FailHelper\" tests=\"1\" failures=\"1\".*")
+	c.Check(string(report), Matches, "(?s).*<testsuite name=\"FixtureHelper\" .*")
+}
+
+func (s *bridgeS) TestSubtestsJSONFlag(c *C) {
+	output := runBridgeHelper(c, "-check.json")
+	c.Check(output, Matches, "(?s).*Can't use -check.json with TestingSubtests, use go test -json instead.*")
+	c.Check(output, Not(Matches), "(?s).*SuccessHelper.*")
+}
+
+func (s *bridgeS) TestSubtestsRunFlag(c *C) {
+	output := runBridgeHelper(c, "-test.run=^TestBridgeHelper$/^FailHelper$")
+	c.Check(output, Matches, "(?s).*--- FAIL: TestBridgeHelper/FailHelper/TestLogAndFail .*")
+	c.Check(output, Not(Matches), "(?s).*SuccessHelper.*")
+}
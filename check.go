@@ -22,6 +22,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"testing"
 	"time"
 )
 
@@ -61,7 +62,10 @@ func (method *methodType) PC() uintptr {
 }
 
 func (method *methodType) suiteName() string {
-	t := method.Info.Type.In(0)
+	return suiteTypeName(method.Info.Type.In(0))
+}
+
+func suiteTypeName(t reflect.Type) string {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
@@ -90,6 +94,23 @@ type C struct {
 	tempDir   *tempDir
 	benchMem  bool
 	startTime time.Time
+	parallel  bool
+	entered   bool // Let in by the parallel gate, holding a slot.
+	paused    chan bool
+	gate      *parallelGate
+	timeout   *time.Timer
+	budget    time.Duration
+	_finished uint32
+	elapsed   time.Duration
+	result    *TestResult
+	runner    *suiteRunner
+	cleanups  *cleanupStack
+	attempt   int
+	retried   bool
+	parent    *C         // Test running the subtest, if any.
+	polledBy  string     // Eventually or Consistently, for their scratch C.
+	tracked   bool       // Owned by the result tracker.
+	t         *testing.T // Running the call, with TestingSubtests.
 	timer
 }
 
@@ -105,6 +126,29 @@ func (c *C) stopNow() {
 	runtime.Goexit()
 }
 
+// subtestPath returns the path of a subtest under its test method, in
+// the form "/name/subname", or an empty string for other calls.
+func (c *C) subtestPath() string {
+	if c.kind == testKd && len(c.testName) > len(c.method.String()) {
+		return c.testName[len(c.method.String()):]
+	}
+	return ""
+}
+
+// root returns the test call running c as a subtest, or c itself.
+func (c *C) root() *C {
+	for c.parent != nil {
+		c = c.parent
+	}
+	return c
+}
+
+// markFinished returns true only to the first caller, so that a call is
+// reported once even if it finishes after having timed out.
+func (c *C) markFinished() bool {
+	return atomic.CompareAndSwapUint32(&c._finished, 0, 1)
+}
+
 // logger is a concurrency safe byte.Buffer
 type logger struct {
 	sync.Mutex
@@ -134,6 +178,92 @@ func (l *logger) String() string {
 	return l.buffer.String()
 }
 
+// -----------------------------------------------------------------------
+// Coordination of tests running in parallel.
+
+// parallelGate holds back the tests which called C.Parallel until all
+// the serial tests in the suite have run, and then lets at most
+// Parallelism of them run at once. Once stopped, as after a failure in
+// fail-fast mode, the tests still waiting aren't let in anymore.
+type parallelGate struct {
+	sem      chan bool
+	release  chan bool
+	stop     chan bool
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func newParallelGate(parallelism int) *parallelGate {
+	if parallelism < 1 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	return &parallelGate{
+		sem:     make(chan bool, parallelism),
+		release: make(chan bool),
+		stop:    make(chan bool),
+	}
+}
+
+// Wait for a free slot to run a parallel test in. Returns false if the
+// gate was stopped instead, and the test must not run.
+func (gate *parallelGate) enter() bool {
+	select {
+	case <-gate.stop:
+		return false
+	default:
+	}
+	select {
+	case gate.sem <- true:
+		return true
+	case <-gate.stop:
+		return false
+	}
+}
+
+// Stop letting the waiting parallel tests in.
+func (gate *parallelGate) stopWaiting() {
+	gate.stopOnce.Do(func() { close(gate.stop) })
+}
+
+// Release the waiting parallel tests and block until they're all done.
+func (gate *parallelGate) releaseAndWait() {
+	close(gate.release)
+	gate.wg.Wait()
+}
+
+// -----------------------------------------------------------------------
+// Functions registered with C.Cleanup.
+
+type cleanupFunc struct {
+	method *methodType
+	f      func()
+}
+
+// cleanupStack holds the functions registered with C.Cleanup for a call,
+// to be run most recent first once the call and its teardown are done.
+type cleanupStack struct {
+	m     sync.Mutex
+	funcs []cleanupFunc
+}
+
+func (s *cleanupStack) push(method *methodType, f func()) {
+	s.m.Lock()
+	s.funcs = append(s.funcs, cleanupFunc{method, f})
+	s.m.Unlock()
+}
+
+func (s *cleanupStack) pop() (cleanupFunc, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	n := len(s.funcs)
+	if n == 0 {
+		return cleanupFunc{}, false
+	}
+	cf := s.funcs[n-1]
+	s.funcs = s.funcs[:n-1]
+	return cf, true
+}
+
 // -----------------------------------------------------------------------
 // Handling of temporary files and directories.
 
@@ -269,6 +399,30 @@ func isMultiLine(s string) bool {
 	return false
 }
 
+// logDiff logs how the obtained value differs from the expected one,
+// if that adds anything to the values being logged by themselves.
+func (c *C) logDiff(obtainedName, expectedName string, obtained, expected interface{}) {
+	obtainedStr, ok1 := obtained.(string)
+	expectedStr, ok2 := expected.(string)
+	if ok1 && ok2 && isMultiLine(obtainedStr) && isMultiLine(expectedStr) {
+		if lines := diffLines(obtainedStr, expectedStr); lines != nil {
+			c.logString("Difference:")
+			c.logf("...     --- %s", obtainedName)
+			c.logf("...     +++ %s", expectedName)
+			for _, line := range lines {
+				c.logf("...     %s", line)
+			}
+		}
+		return
+	}
+	if diffs := diffValues(obtained, expected); len(diffs) > 0 {
+		c.logString("Difference:")
+		for _, diff := range diffs {
+			c.logf("...     %s", diff)
+		}
+	}
+}
+
 func (c *C) logString(issue string) {
 	c.log("... ", issue)
 }
@@ -350,6 +504,28 @@ func (c *C) logSoftPanic(issue string) {
 	c.log("... Panic: ", issue)
 }
 
+func (c *C) logTimeout() {
+	c.logf("... Timeout: call exceeded %s, goroutines were:\n", c.budget)
+	c.writeLog(allStacks())
+}
+
+func (c *C) logLeaks(stacks []string) {
+	c.logf("... Leak: %d goroutine(s) started by the test still running:\n", len(stacks))
+	c.writeLog([]byte(strings.Join(stacks, "\n\n") + "\n"))
+}
+
+// Return the stacks of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
 func (c *C) logArgPanic(method *methodType, expectedType string) {
 	c.logf("... Panic: %s argument should be %s",
 		niceFuncName(method.PC()), expectedType)
@@ -418,8 +594,31 @@ type Result struct {
 	FixturePanicked  int
 	ExpectedFailures int
 	Missed           int    // Not even tried to run, related to a panic in the fixture.
+	Flaky            int    // Failed, but then passed when retried.
 	RunError         error  // Houston, we've got a problem.
 	WorkDir          string // If KeepWorkDir is true
+	ShuffleSeed      int64  // If Shuffle is true
+
+	// Tests holds the outcome of every test method tracked, in the
+	// order they finished.
+	Tests []TestResult
+}
+
+// TestResult holds the outcome of a single test method.
+type TestResult struct {
+	Suite     string
+	Method    string
+	Status    EventType // Type of the event reporting the test as done.
+	Duration  time.Duration
+	Reason    string
+	Log       string
+	Benchmark *BenchmarkResult // Only set for benchmarks which passed.
+	Retried   bool             // Failed, and was run again.
+}
+
+// Name returns the test name in the form "SuiteName.MethodName".
+func (r *TestResult) Name() string {
+	return r.Suite + "." + r.Method
 }
 
 type resultTracker struct {
@@ -430,12 +629,14 @@ type resultTracker struct {
 	_expectChan     chan *C
 	_doneChan       chan *C
 	_stopChan       chan bool
+	_subtests       map[*C][]*C // Done subtests, by their root test.
 }
 
 func newResultTracker() *resultTracker {
 	return &resultTracker{_expectChan: make(chan *C), // Synchronous
 		_doneChan: make(chan *C, 32), // Asynchronous
-		_stopChan: make(chan bool)}   // Synchronous
+		_stopChan: make(chan bool),   // Synchronous
+		_subtests: make(map[*C][]*C)}
 }
 
 func (tracker *resultTracker) start() {
@@ -465,34 +666,22 @@ func (tracker *resultTracker) _loopRoutine() {
 				tracker._waiting += 1
 			case c = <-tracker._doneChan:
 				tracker._waiting -= 1
-				switch c.status() {
-				case succeededSt:
-					if c.kind == testKd {
-						if c.mustFail {
-							tracker.result.ExpectedFailures++
-						} else {
-							tracker.result.Succeeded++
-						}
-					}
-				case failedSt:
-					tracker.result.Failed++
-				case panickedSt:
-					if c.kind == fixtureKd {
-						tracker.result.FixturePanicked++
-					} else {
-						tracker.result.Panicked++
-					}
-				case fixturePanickedSt:
-					// Track it as missed, since the panic
-					// was on the fixture, not on the test.
-					tracker.result.Missed++
-				case missedSt:
-					tracker.result.Missed++
-				case skippedSt:
-					if c.kind == testKd {
-						tracker.result.Skipped++
+				if root := c.root(); root != c && !root.tracked {
+					// Subtests count once it's known whether
+					// their test is retried.
+					tracker._subtests[root] = append(tracker._subtests[root], c)
+					continue
+				}
+				for _, sub := range tracker._subtests[c] {
+					if c.retried {
+						sub.retried = true
+						sub.result.Retried = true
 					}
+					tracker.track(sub)
 				}
+				delete(tracker._subtests, c)
+				c.tracked = true
+				tracker.track(c)
 			}
 		} else {
 			// No calls.  Can stop, but no done calls here.
@@ -508,6 +697,47 @@ func (tracker *resultTracker) _loopRoutine() {
 	}
 }
 
+// Add a done call to the results.
+func (tracker *resultTracker) track(c *C) {
+	if c.result != nil {
+		tracker.result.Tests = append(tracker.result.Tests, *c.result)
+	}
+	if c.retried {
+		// Only the last attempt counts.
+		return
+	}
+	switch c.status() {
+	case succeededSt:
+		if c.kind == testKd {
+			if c.mustFail {
+				tracker.result.ExpectedFailures++
+			} else if c.attempt > 0 {
+				tracker.result.Flaky++
+			} else {
+				tracker.result.Succeeded++
+			}
+		}
+	case failedSt:
+		tracker.result.Failed++
+	case panickedSt:
+		if c.kind == fixtureKd {
+			tracker.result.FixturePanicked++
+		} else {
+			tracker.result.Panicked++
+		}
+	case fixturePanickedSt:
+		// Track it as missed, since the panic
+		// was on the fixture, not on the test.
+		tracker.result.Missed++
+	case missedSt:
+		tracker.result.Missed++
+	case skippedSt:
+		if c.kind == testKd {
+			tracker.result.Skipped++
+		}
+	}
+}
+
 // -----------------------------------------------------------------------
 // The underlying suite runner.
 
@@ -525,6 +755,17 @@ type suiteRunner struct {
 	benchTime                 time.Duration
 	benchMem                  bool
 	verbosity                 uint8
+	gate                      *parallelGate
+	testTimeout               time.Duration
+	timedOut                  sync.WaitGroup // Timed-out calls still running.
+	filters                   []*regexp.Regexp
+	failFast                  bool
+	testingT                  *testing.T
+	output                    *outputWriter
+	count, retry              int
+	detectLeaks               bool
+	leakGrace                 time.Duration
+	leakAllowlist             []string
 }
 
 type RunConf struct {
@@ -532,10 +773,41 @@ type RunConf struct {
 	Stream        bool
 	Verbose       bool
 	Filter        string
+	Exclude       string // Leaves out the tests matching it, like Filter
+	Tags          string // Expression on the tags of the tests, see Tagger
 	Benchmark     bool
 	BenchmarkTime time.Duration // Defaults to 1 second
 	BenchmarkMem  bool
 	KeepWorkDir   bool
+	Parallelism   int           // Defaults to GOMAXPROCS
+	JUnitOutput   io.Writer     // Writes a JUnit XML report if set
+	JSONOutput    io.Writer     // Streams "go test -json" events if set
+	Reporters     []Reporter    // Notified of events besides Output
+	Shuffle       bool          // Run suites and tests in random order
+	ShuffleSeed   int64         // Defaults to the current time
+	FailFast      bool          // Stop running tests after the first failure
+	Count         int           // Times to run each test, defaults to 1
+	Retry         int           // Times to run a failed test again, unless it called C.Parallel
+	DetectLeaks   bool          // Fail tests leaving goroutines behind
+	LeakGrace     time.Duration // Time for goroutines to end, defaults to 1 second
+	LeakAllowlist []string      // Goroutines whose stack has any of these aren't leaks
+	Shard         int           // Run only the tests of this shard, from 1
+	Shards        int           // Number of shards, if sharding
+
+	// TestTimeout fails tests running longer than it, leaving them behind
+	// in their goroutine. The time covers the whole test call: SetUpTest,
+	// the method, TearDownTest, the cleanups and, with DetectLeaks, the
+	// leak grace period. Whatever remains of a timed-out test runs
+	// concurrently with the tests that come after. Before TearDownSuite,
+	// the suite waits up to TestTimeout once more for such tests to
+	// return; the TearDownTest and cleanups of those still running then
+	// may never run. Suite fixtures and benchmarks have no timeout.
+	// Defaults to no timeout.
+	TestTimeout time.Duration
+
+	// ShardTimings balances the shards with how long each test, named
+	// as "Suite.Method", took in a previous run. See ReadShardTimings.
+	ShardTimings map[string]time.Duration
 }
 
 // Create a new suiteRunner able to run all methods in the given suite.
@@ -562,30 +834,73 @@ func newSuiteRunner(suite interface{}, runConf *RunConf) *suiteRunner {
 		verbosity = 2
 	}
 
+	output := newOutputWriter(conf.Output, verbosity)
 	runner := &suiteRunner{
-		suite:     suite,
-		logOutput: conf.Output,
-		reporter:  newOutputWriter(conf.Output, verbosity),
-		tracker:   newResultTracker(),
-		benchTime: conf.BenchmarkTime,
-		benchMem:  conf.BenchmarkMem,
-		tempDir:   &tempDir{},
-		keepDir:   conf.KeepWorkDir,
-		tests:     make([]*methodType, 0, suiteNumMethods),
-		verbosity: verbosity,
+		suite:       suite,
+		logOutput:   conf.Output,
+		output:      output,
+		reporter:    output,
+		tracker:     newResultTracker(),
+		benchTime:   conf.BenchmarkTime,
+		benchMem:    conf.BenchmarkMem,
+		tempDir:     &tempDir{},
+		keepDir:     conf.KeepWorkDir,
+		tests:       make([]*methodType, 0, suiteNumMethods),
+		verbosity:   verbosity,
+		gate:        newParallelGate(conf.Parallelism),
+		testTimeout: conf.TestTimeout,
+		failFast:    conf.FailFast,
+		count:       conf.Count,
+		retry:       conf.Retry,
+		detectLeaks: conf.DetectLeaks,
+		leakGrace:   conf.LeakGrace,
+	}
+	if runner.leakGrace == 0 {
+		runner.leakGrace = 1 * time.Second
+	}
+	runner.leakAllowlist = append(defaultLeakAllowlist, conf.LeakAllowlist...)
+	if runner.count < 1 {
+		runner.count = 1
 	}
 	if runner.benchTime == 0 {
 		runner.benchTime = 1 * time.Second
 	}
+	if len(conf.Reporters) > 0 {
+		runner.addReporter(publicReporters(conf.Reporters))
+	}
+	if conf.JSONOutput != nil {
+		runner.addReporter(newJSONReport(conf.JSONOutput))
+	}
 
-	var filterRegexp *regexp.Regexp
 	if conf.Filter != "" {
-		if regexp, err := regexp.Compile(conf.Filter); err != nil {
-			msg := "Bad filter expression: " + err.Error()
+		for _, part := range splitFilter(conf.Filter) {
+			if regexp, err := regexp.Compile(part); err != nil {
+				msg := "Bad filter expression: " + err.Error()
+				runner.tracker.result.RunError = errors.New(msg)
+				return runner
+			} else {
+				runner.filters = append(runner.filters, regexp)
+			}
+		}
+	}
+
+	var exclude *regexp.Regexp
+	if conf.Exclude != "" {
+		if regexp, err := regexp.Compile(conf.Exclude); err != nil {
+			msg := "Bad exclude expression: " + err.Error()
 			runner.tracker.result.RunError = errors.New(msg)
 			return runner
 		} else {
-			filterRegexp = regexp
+			exclude = regexp
+		}
+	}
+
+	var tags tagsExpr
+	if conf.Tags != "" {
+		var err error
+		if tags, err = parseTags(conf.Tags); err != nil {
+			runner.tracker.result.RunError = err
+			return runner
 		}
 	}
 
@@ -608,14 +923,69 @@ func newSuiteRunner(suite interface{}, runConf *RunConf) *suiteRunner {
 			if !strings.HasPrefix(method.Info.Name, prefix) {
 				continue
 			}
-			if filterRegexp == nil || method.matches(filterRegexp) {
-				runner.tests = append(runner.tests, method)
+			if runner.filters != nil && !method.matches(runner.filters[0]) {
+				continue
+			}
+			if exclude != nil && method.matches(exclude) {
+				continue
 			}
+			if tags != nil && !tags(methodTags(suite, method)) {
+				continue
+			}
+			runner.tests = append(runner.tests, method)
 		}
 	}
+	if conf.Shuffle {
+		seed := shuffleSeed(&conf)
+		rand.New(rand.NewSource(seed)).Shuffle(len(runner.tests), func(i, j int) {
+			runner.tests[i], runner.tests[j] = runner.tests[j], runner.tests[i]
+		})
+		runner.tracker.result.ShuffleSeed = seed
+	}
 	return runner
 }
 
+// Split the filter expression on the slashes separating the expressions
+// for test methods and for each level of subtests, ignoring any slashes
+// within brackets or parenthesis, or escaped.
+func splitFilter(filter string) []string {
+	var parts []string
+	var depth int
+	start := 0
+	for i := 0; i < len(filter); i++ {
+		switch filter[i] {
+		case '\\':
+			i++
+		case '[', '(':
+			depth++
+		case ']', ')':
+			if depth > 0 {
+				depth--
+			}
+		case '/':
+			if depth == 0 {
+				parts = append(parts, filter[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, filter[start:])
+}
+
+// Returns the seed to shuffle tests with, picking one if necessary.
+func shuffleSeed(conf *RunConf) int64 {
+	if conf.ShuffleSeed == 0 {
+		return time.Now().UnixNano()
+	}
+	return conf.ShuffleSeed
+}
+
+// Report events to the given reporter as well. The output writer consumes
+// the call log when reporting problems, so it must remain the last one.
+func (runner *suiteRunner) addReporter(reporter testReporter) {
+	runner.reporter = multiReporter{reporter, runner.reporter}
+}
+
 // Run all methods in the given suite.
 func (runner *suiteRunner) run() *Result {
 	if runner.tracker.result.RunError == nil && len(runner.tests) > 0 {
@@ -624,22 +994,37 @@ func (runner *suiteRunner) run() *Result {
 			c := runner.runFixture(runner.setUpSuite, "", nil)
 			if c == nil || c.status() == succeededSt {
 				for i := 0; i != len(runner.tests); i++ {
-					c := runner.runTest(runner.tests[i])
+					c := runner.runTestCount(runner.tests[i])
+					if c == nil {
+						continue
+					}
 					if c.status() == fixturePanickedSt {
-						runner.skipTests(missedSt, runner.tests[i+1:])
+						runner.skipTests(missedSt, "", runner.tests[i+1:])
+						break
+					}
+					if runner.failFast && (c.status() == failedSt || c.status() == panickedSt) {
+						runner.skipTests(missedSt, notRunReason, runner.tests[i+1:])
 						break
 					}
 				}
+				runner.gate.releaseAndWait()
+				runner.waitTimedOut()
 			} else if c != nil && c.status() == skippedSt {
-				runner.skipTests(skippedSt, runner.tests)
+				runner.skipTests(skippedSt, "", runner.tests)
 			} else {
-				runner.skipTests(missedSt, runner.tests)
+				runner.skipTests(missedSt, "", runner.tests)
 			}
 			runner.runFixture(runner.tearDownSuite, "", nil)
+			if c != nil {
+				runner.runCleanups(c.cleanups, "")
+			}
 		} else {
-			runner.skipTests(missedSt, runner.tests)
+			runner.skipTests(missedSt, "", runner.tests)
 		}
 		runner.tracker.waitAndStop()
+		if runner.count > 1 {
+			runner.reportPassRatios()
+		}
 		if runner.keepDir {
 			runner.tracker.result.WorkDir = runner.tempDir.path
 		} else {
@@ -649,9 +1034,44 @@ func (runner *suiteRunner) run() *Result {
 	return &runner.tracker.result
 }
 
+// Wait for the calls which have timed out to return, so that their
+// TearDownTest and cleanups run before the suite is torn down and its
+// work directory removed. Calls still running after the test timeout
+// has passed once more are abandoned.
+func (runner *suiteRunner) waitTimedOut() {
+	done := make(chan bool)
+	go func() {
+		runner.timedOut.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(runner.testTimeout):
+	}
+}
+
+// Mark all methods in the given suite as missed without running any of
+// them, not even the suite fixtures. Used in fail-fast mode once an
+// earlier suite has failed.
+func (runner *suiteRunner) runNone() *Result {
+	if runner.tracker.result.RunError == nil && len(runner.tests) > 0 {
+		runner.tracker.start()
+		runner.skipTests(missedSt, notRunReason, runner.tests)
+		runner.tracker.waitAndStop()
+	}
+	return &runner.tracker.result
+}
+
 // Create a call object with the given suite method, and fork a
 // goroutine with the provided dispatcher for running it.
 func (runner *suiteRunner) forkCall(method *methodType, kind funcKind, testName string, logb *logger, dispatcher func(c *C)) *C {
+	c := runner.newCall(method, kind, testName, logb)
+	runner.startCall(c, dispatcher)
+	return c
+}
+
+// Create a call object with the given suite method, not yet started.
+func (runner *suiteRunner) newCall(method *methodType, kind funcKind, testName string, logb *logger) *C {
 	if logb == nil {
 		logb = &logger{
 			output:    runner.logOutput,
@@ -668,14 +1088,25 @@ func (runner *suiteRunner) forkCall(method *methodType, kind funcKind, testName
 		timer:     timer{benchTime: runner.benchTime},
 		startTime: time.Now(),
 		benchMem:  runner.benchMem,
+		paused:    make(chan bool, 1),
+		gate:      runner.gate,
+		runner:    runner,
+		cleanups:  &cleanupStack{},
 	}
+	return c
+}
+
+// Fork a goroutine with the provided dispatcher for running the call.
+func (runner *suiteRunner) startCall(c *C, dispatcher func(c *C)) {
 	runner.tracker.expectCall(c)
+	if c.budget > 0 {
+		c.timeout = time.AfterFunc(c.budget, func() { runner.callTimedOut(c) })
+	}
 	go (func() {
 		runner.reportCallStarted(c)
 		defer runner.callDone(c)
 		dispatcher(c)
 	})()
-	return c
 }
 
 // Same as forkCall(), but wait for call to finish before returning.
@@ -689,6 +1120,14 @@ func (runner *suiteRunner) runFunc(method *methodType, kind funcKind, testName s
 // accordingly.  Then, mark the call as done and report to the tracker.
 func (runner *suiteRunner) callDone(c *C) {
 	value := recover()
+	if c.timeout != nil {
+		c.timeout.Stop()
+	}
+	if !c.markFinished() {
+		// Timed out and already reported. Nothing else to do.
+		runner.timedOut.Done()
+		return
+	}
 	if value != nil {
 		switch v := value.(type) {
 		case *fixturePanic:
@@ -714,17 +1153,59 @@ func (runner *suiteRunner) callDone(c *C) {
 		}
 	}
 
+	runner.finishCall(c)
+}
+
+// Handle a call which is still running after its timeout has expired.
+// The call is marked as failed with a dump of all goroutines, and reported
+// as done so that the runner may move on. The call's goroutine is left
+// behind, and its result isn't reported anymore, but the suite waits for
+// it for a while before running TearDownSuite. See waitTimedOut.
+func (runner *suiteRunner) callTimedOut(c *C) {
+	runner.timedOut.Add(1)
+	if !c.markFinished() {
+		runner.timedOut.Done()
+		return
+	}
+	c.logTimeout()
+	c.setStatus(failedSt)
+	runner.finishCall(c)
+}
+
+func (runner *suiteRunner) finishCall(c *C) {
+	c.elapsed = time.Now().Sub(c.startTime)
 	runner.reportCallDone(c)
+	if runner.failFast && c.kind == testKd && !c.retried {
+		switch c.status() {
+		case failedSt, panickedSt:
+			// Before the slot is freed for another test.
+			c.gate.stopWaiting()
+		}
+	}
+	if c.parallel {
+		if c.entered {
+			<-c.gate.sem
+		}
+		c.gate.wg.Done()
+	}
 	c.done <- c
 }
 
 // Runs a fixture call synchronously.  The fixture will still be run in a
 // goroutine like all suite methods, but this method will not return
 // while the fixture goroutine is not done, because the fixture must be
-// run in a desired order.
-func (runner *suiteRunner) runFixture(method *methodType, testName string, logb *logger) *C {
+// run in a desired order.  If a parent call is given, the fixture shares
+// its log and its cleanups.
+func (runner *suiteRunner) runFixture(method *methodType, testName string, parent *C) *C {
 	if method != nil {
+		var logb *logger
+		if parent != nil {
+			logb = parent.logb
+		}
 		c := runner.runFunc(method, fixtureKd, testName, logb, func(c *C) {
+			if parent != nil {
+				c.cleanups = parent.cleanups
+			}
 			c.ResetTimer()
 			c.StartTimer()
 			defer c.StopTimer()
@@ -738,11 +1219,11 @@ func (runner *suiteRunner) runFixture(method *methodType, testName string, logb
 // Run the fixture method with runFixture(), but panic with a fixturePanic{}
 // in case the fixture method panics.  This makes it easier to track the
 // fixture panic together with other call panics within forkTest().
-func (runner *suiteRunner) runFixtureWithPanic(method *methodType, testName string, logb *logger, skipped *bool) *C {
+func (runner *suiteRunner) runFixtureWithPanic(method *methodType, testName string, parent *C, skipped *bool) *C {
 	if skipped != nil && *skipped {
 		return nil
 	}
-	c := runner.runFixture(method, testName, logb)
+	c := runner.runFixture(method, testName, parent)
 	if c != nil && c.status() != succeededSt {
 		if skipped != nil {
 			*skipped = c.status() == skippedSt
@@ -757,17 +1238,57 @@ type fixturePanic struct {
 	method *methodType
 }
 
+// Run the functions registered with C.Cleanup, most recent first. Each
+// one is run as a fixture call of the method which registered it, and
+// they all run even if some of them panic. Returns the first call which
+// didn't succeed, if any.
+func (runner *suiteRunner) runCleanups(cleanups *cleanupStack, testName string) *C {
+	var problem *C
+	for {
+		cf, ok := cleanups.pop()
+		if !ok {
+			return problem
+		}
+		c := runner.runFunc(cf.method, fixtureKd, testName, nil, func(c *C) {
+			c.cleanups = cleanups
+			cf.f()
+		})
+		if problem == nil && c.status() != succeededSt {
+			problem = c
+		}
+	}
+}
+
+// Run the cleanups with runCleanups(), but panic with a fixturePanic{}
+// in case any of them panics, like runFixtureWithPanic() does.
+func (runner *suiteRunner) runCleanupsWithPanic(cleanups *cleanupStack, testName string) {
+	if c := runner.runCleanups(cleanups, testName); c != nil {
+		panic(&fixturePanic{c.status(), c.method})
+	}
+}
+
 // Run the suite test method, together with the test-specific fixture,
-// asynchronously.
-func (runner *suiteRunner) forkTest(method *methodType) *C {
+// asynchronously. The attempt is the number of times the test has been
+// retried after failing, and t the subtest running it, if any.
+func (runner *suiteRunner) forkTest(method *methodType, attempt int, t *testing.T) *C {
 	testName := method.String()
-	return runner.forkCall(method, testKd, testName, nil, func(c *C) {
+	c := runner.newCall(method, testKd, testName, nil)
+	c.attempt = attempt
+	c.t = t
+	if !strings.HasPrefix(method.Info.Name, "Benchmark") {
+		c.budget = runner.testTimeout
+	}
+	runner.startCall(c, func(c *C) {
+		if runner.detectLeaks {
+			defer runner.checkLeaks(c, goroutineStacks())
+		}
 		var skipped bool
+		defer runner.runCleanupsWithPanic(c.cleanups, testName)
 		defer runner.runFixtureWithPanic(runner.tearDownTest, testName, nil, &skipped)
 		defer c.StopTimer()
 		benchN := 1
 		for {
-			runner.runFixtureWithPanic(runner.setUpTest, testName, c.logb, &skipped)
+			runner.runFixtureWithPanic(runner.setUpTest, testName, c, &skipped)
 			mt := c.method.Type()
 			if mt.NumIn() != 1 || mt.In(0) != reflect.TypeOf(c) {
 				// Rather than a plain panic, provide a more helpful message when
@@ -809,26 +1330,163 @@ func (runner *suiteRunner) forkTest(method *methodType) *C {
 
 			skipped = true // Don't run the deferred one if this panics.
 			runner.runFixtureWithPanic(runner.tearDownTest, testName, nil, nil)
+			runner.runCleanupsWithPanic(c.cleanups, testName)
 			skipped = false
 		}
 	})
+	return c
+}
+
+// Run the test as many times as RunConf.Count asks for, retrying it when
+// it fails as RunConf.Retry allows, and return the last call. Stops
+// early when a run shouldn't be followed by any other.
+func (runner *suiteRunner) runTestCount(method *methodType) *C {
+	var c *C
+	for n := 0; n < runner.count; n++ {
+		for attempt := 0; ; attempt++ {
+			c = runner.runTest(method, attempt)
+			// The call of a paused test must not be looked at
+			// before it's done, but such tests aren't retried.
+			if c == nil || c.parallel || !c.retried {
+				break
+			}
+		}
+		if c == nil {
+			break
+		}
+		if c.parallel {
+			continue
+		}
+		switch c.status() {
+		case fixturePanickedSt:
+			return c
+		case failedSt, panickedSt:
+			if runner.failFast {
+				return c
+			}
+		}
+	}
+	return c
+}
+
+// Whether the finished test call has failed and should be run again.
+// Tests running in parallel and subtests are never retried.
+func (runner *suiteRunner) willRetry(c *C) bool {
+	if c.attempt >= runner.retry || c.parallel || c.subtestPath() != "" {
+		return false
+	}
+	switch c.status() {
+	case failedSt, panickedSt:
+		return !c.mustFail
+	}
+	return false
+}
+
+// Report how many of the times each test was run it has passed on the
+// first attempt, and how many it only passed when retried. Only tests
+// which didn't always pass at once are reported unless running in
+// verbose mode.
+func (runner *suiteRunner) reportPassRatios() {
+	for _, method := range runner.tests {
+		var passed, flaky, total int
+		var retried bool
+		for _, test := range runner.tracker.result.Tests {
+			if test.Name() != method.String() {
+				continue
+			}
+			if test.Retried {
+				retried = true
+				continue
+			}
+			total++
+			switch test.Status {
+			case EventPass, EventExpectedFailure, EventSkip:
+				if retried {
+					flaky++
+				} else {
+					passed++
+				}
+			}
+			retried = false
+		}
+		if total > 0 {
+			runner.output.writePassRatio(method, passed, flaky, total)
+		}
+	}
+}
+
+// Same as forkTest(), but wait for the test to finish before returning,
+// or for it to be paused after calling C.Parallel. When run through
+// TestingSubtests, nil is returned if the test was left out by -test.run.
+func (runner *suiteRunner) runTest(method *methodType, attempt int) *C {
+	if runner.testingT != nil {
+		return runner.runTestT(method, func(method *methodType, t *testing.T) *C {
+			return runner.forkTest(method, attempt, t)
+		})
+	}
+	c := runner.forkTest(method, attempt, nil)
+	select {
+	case <-c.done:
+	case <-c.paused:
+	}
+	return c
 }
 
-// Same as forkTest(), but wait for the test to finish before returning.
-func (runner *suiteRunner) runTest(method *methodType) *C {
-	c := runner.forkTest(method)
+// Run f as a subtest of the given parent call, and wait for it to finish.
+// Subtests don't run the test fixtures, and are reported and counted on
+// their own. With TestingSubtests, they're run as subtests of the parent's
+// testing.T as well. If the subtest is filtered out, nil is returned.
+func (runner *suiteRunner) runSubtest(parent *C, name string, f func(c *C)) *C {
+	name = strings.Replace(name, " ", "_", -1)
+	level := strings.Count(parent.subtestPath(), "/") + 1
+	if level < len(runner.filters) && !runner.filters[level].MatchString(name) {
+		return nil
+	}
+	if parent.t != nil {
+		return runner.runSubtestT(parent, name, f)
+	}
+	c := runner.forkSubtest(parent, name, f, nil)
 	<-c.done
 	return c
 }
 
+// Run f as a subtest of the given parent call asynchronously, with t
+// being the subtest running it, if any.
+func (runner *suiteRunner) forkSubtest(parent *C, name string, f func(c *C), t *testing.T) *C {
+	testName := parent.testName + "/" + name
+	c := runner.newCall(parent.method, testKd, testName, nil)
+	c.parent = parent
+	c.t = t
+	runner.startCall(c, func(c *C) {
+		defer runner.runCleanupsWithPanic(c.cleanups, testName)
+		c.ResetTimer()
+		c.StartTimer()
+		defer c.StopTimer()
+		f(c)
+	})
+	return c
+}
+
+// Reason given for the tests missed because of an earlier failure when
+// running in fail-fast mode.
+const notRunReason = "not run"
+
 // Helper to mark tests as skipped or missed.  A bit heavy for what
 // it does, but it enables homogeneous handling of tracking, including
 // nice verbose output.
-func (runner *suiteRunner) skipTests(status funcStatus, methods []*methodType) {
+func (runner *suiteRunner) skipTests(status funcStatus, reason string, methods []*methodType) {
 	for _, method := range methods {
-		runner.runFunc(method, testKd, "", nil, func(c *C) {
-			c.setStatus(status)
-		})
+		fork := func(method *methodType, t *testing.T) *C {
+			return runner.forkCall(method, testKd, "", nil, func(c *C) {
+				c.reason = reason
+				c.setStatus(status)
+			})
+		}
+		if runner.testingT != nil {
+			runner.runTestT(method, fork)
+		} else {
+			<-fork(method, nil).done
+		}
 	}
 }
 
@@ -857,27 +1515,36 @@ func (runner *suiteRunner) reportCallStarted(c *C) {
 }
 
 func (runner *suiteRunner) reportCallDone(c *C) {
-	runner.tracker.callDone(c)
+	var e event
 	switch c.status() {
 	case succeededSt:
 		if c.mustFail {
-			runner.reporter.Report(expectedFailure, c)
+			e = expectedFailure
 		} else {
-			runner.reporter.Report(success, c)
+			e = success
 		}
 	case skippedSt:
-		runner.reporter.Report(skip, c)
+		e = skip
 	case failedSt:
-		runner.reporter.Report(failure, c)
+		e = failure
 	case panickedSt:
-		runner.reporter.Report(panicked, c)
+		e = panicked
 	case fixturePanickedSt:
 		// That's a testKd call reporting that its fixture
 		// has panicked. The fixture call which caused the
 		// panic itself was tracked above. We'll report to
 		// aid debugging.
-		runner.reporter.Report(panicked, c)
+		e = panicked
 	case missedSt:
-		runner.reporter.Report(missed, c)
+		e = missed
+	}
+	if c.kind == testKd {
+		c.retried = runner.willRetry(c)
+		// Taken before reporting, since the output writer
+		// consumes the log.
+		c.result = newTestResult(newEvent(e, c))
+		c.result.Retried = c.retried
 	}
+	runner.tracker.callDone(c)
+	runner.reporter.Report(e, c)
 }
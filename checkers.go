@@ -1,10 +1,19 @@
 package check
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/big"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 )
 
 // -----------------------------------------------------------------------
@@ -63,6 +72,30 @@ func (info *CheckerInfo) Info() *CheckerInfo {
 	return info
 }
 
+// diffChecker is implemented by the checkers comparing the obtained value
+// against the expected one, so that their differences are logged when the
+// check fails.
+type diffChecker interface {
+	Checker
+	comparesValues()
+}
+
+// typedChecker is implemented by the checkers comparing the obtained value
+// against expected ones which must be of the same type to ever match, so
+// that Each and Any report a type error instead. The indexes of those
+// among the checker's parameters are returned, if any.
+type typedChecker interface {
+	Checker
+	sameTypeParams() []int
+}
+
+func sameTypeParams(checker Checker) []int {
+	if typed, ok := checker.(typedChecker); ok {
+		return typed.sameTypeParams()
+	}
+	return nil
+}
+
 // -----------------------------------------------------------------------
 // Not checker logic inverter.
 
@@ -89,11 +122,181 @@ func (checker *notChecker) Info() *CheckerInfo {
 }
 
 func (checker *notChecker) Check(params []interface{}, names []string) (result bool, error string) {
-	result, error = checker.sub.Check(params, names)
+	if explained, ok := checker.sub.(explainedChecker); ok {
+		// Why the checker didn't match is moot once inverted.
+		result, _, error = explained.checkExplained(params, names)
+	} else {
+		result, error = checker.sub.Check(params, names)
+	}
 	result = !result
 	return
 }
 
+func (checker *notChecker) sameTypeParams() []int {
+	return sameTypeParams(checker.sub)
+}
+
+// explainedChecker is implemented by checkers which explain why a check
+// didn't match, apart from the errors which make it fail regardless.
+type explainedChecker interface {
+	checkExplained(params []interface{}, names []string) (result bool, explanation, error string)
+}
+
+// Run checker, returning why it didn't match, or the error it reported.
+func checkExplained(checker Checker, params []interface{}, names []string) (result bool, explanation, error string) {
+	if explained, ok := checker.(explainedChecker); ok {
+		return explained.checkExplained(params, names)
+	}
+	result, error = checker.Check(params, names)
+	return result, "", error
+}
+
+// Report both the explanation and the error of a failed check as an error.
+func explainedResult(result bool, explanation, error string) (bool, string) {
+	if result || explanation == "" {
+		return result, error
+	}
+	if error == "" {
+		return false, explanation
+	}
+	return false, explanation + "; " + error
+}
+
+// -----------------------------------------------------------------------
+// Checker combinators.
+
+// The AllOf checker succeeds when all the provided checkers succeed on the
+// obtained value. The expected arguments of each checker, if any, follow
+// the obtained value in order. When the check fails, the checkers which
+// didn't succeed are reported.
+//
+// For example:
+//
+//     c.Assert(port, AllOf(GreaterThan, Not(Equals)), 1024, 8080)
+//
+func AllOf(checkers ...Checker) Checker {
+	return newCombinedChecker("AllOf", true, checkers)
+}
+
+// The AnyOf checker succeeds when at least one of the provided checkers
+// succeeds on the obtained value, with the expected arguments of each
+// checker following the obtained value in order, as with AllOf. Errors
+// of a checker only count as it not succeeding. When the check fails,
+// all the checkers are reported.
+//
+// For example:
+//
+//     c.Assert(err, AnyOf(IsNil, ErrorIs), os.ErrNotExist)
+//
+func AnyOf(checkers ...Checker) Checker {
+	return newCombinedChecker("AnyOf", false, checkers)
+}
+
+// The And checker succeeds when both the provided checkers succeed, as
+// with AllOf.
+//
+// For example:
+//
+//     c.Assert(n, And(GreaterOrEqual, LessThan), 0, len(list))
+//
+func And(first, second Checker) Checker {
+	return newCombinedChecker("And", true, []Checker{first, second})
+}
+
+// The Or checker succeeds when either of the provided checkers succeeds,
+// as with AnyOf.
+//
+// For example:
+//
+//     c.Assert(state, Or(Equals, Equals), "running", "stopped")
+//
+func Or(first, second Checker) Checker {
+	return newCombinedChecker("Or", false, []Checker{first, second})
+}
+
+type combinedChecker struct {
+	name string
+	all  bool
+	subs []Checker
+}
+
+func newCombinedChecker(name string, all bool, subs []Checker) Checker {
+	if len(subs) == 0 {
+		panic(name + " called without checkers")
+	}
+	return &combinedChecker{name, all, subs}
+}
+
+func (checker *combinedChecker) Info() *CheckerInfo {
+	var subNames []string
+	params := []string{checker.subs[0].Info().Params[0]}
+	for _, sub := range checker.subs {
+		info := sub.Info()
+		subNames = append(subNames, info.Name)
+		params = append(params, info.Params[1:]...)
+	}
+	return &CheckerInfo{
+		Name:   checker.name + "(" + strings.Join(subNames, ", ") + ")",
+		Params: params,
+	}
+}
+
+func (checker *combinedChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	return explainedResult(checker.checkExplained(params, names))
+}
+
+func (checker *combinedChecker) checkExplained(params []interface{}, names []string) (result bool, explanation, error string) {
+	var explanations, errs []string
+	matched := 0
+	next := 1
+	for _, sub := range checker.subs {
+		info := sub.Info()
+		end := next + len(info.Params) - 1
+		subParams := append([]interface{}{params[0]}, params[next:end]...)
+		subNames := append([]string{names[0]}, names[next:end]...)
+		subResult, subExplanation, subError := checkExplained(sub, subParams, subNames)
+		// Expected values may be changed for reporting, as ErrorMatches does.
+		copy(params[next:end], subParams[1:])
+		copy(names[next:end], subNames[1:])
+		next = end
+
+		switch {
+		case subError != "":
+			explanations = append(explanations, info.Name+": "+subError)
+			errs = append(errs, info.Name+": "+subError)
+		case !subResult && subExplanation != "":
+			explanations = append(explanations, info.Name+" didn't match: "+subExplanation)
+		case !subResult:
+			explanations = append(explanations, info.Name+" didn't match")
+		default:
+			matched++
+		}
+	}
+	if checker.all {
+		// Errors of any checker make the whole check fail.
+		if len(errs) > 0 {
+			return false, "", strings.Join(errs, "; ")
+		}
+		return matched == len(checker.subs), strings.Join(explanations, "; "), ""
+	}
+	if matched > 0 {
+		return true, "", ""
+	}
+	return false, strings.Join(explanations, "; "), ""
+}
+
+func (checker *combinedChecker) sameTypeParams() []int {
+	var indexes []int
+	next := 1
+	for _, sub := range checker.subs {
+		for _, i := range sameTypeParams(sub) {
+			indexes = append(indexes, next+i-1)
+		}
+		next += len(sub.Info().Params) - 1
+	}
+	return indexes
+}
+
 // -----------------------------------------------------------------------
 // IsNil checker.
 
@@ -179,6 +382,10 @@ func (checker *equalsChecker) Check(params []interface{}, names []string) (resul
 	return params[0] == params[1], ""
 }
 
+func (checker *equalsChecker) comparesValues() {}
+
+func (checker *equalsChecker) sameTypeParams() []int { return []int{1} }
+
 // -----------------------------------------------------------------------
 // DeepEquals checker.
 
@@ -204,6 +411,10 @@ func (checker *deepEqualsChecker) Check(params []interface{}, names []string) (r
 	return reflect.DeepEqual(params[0], params[1]), ""
 }
 
+func (checker *deepEqualsChecker) comparesValues() {}
+
+func (checker *deepEqualsChecker) sameTypeParams() []int { return []int{1} }
+
 // -----------------------------------------------------------------------
 // HasLen checker.
 
@@ -258,18 +469,151 @@ var ErrorMatches Checker = errorMatchesChecker{
 }
 
 func (checker errorMatchesChecker) Check(params []interface{}, names []string) (result bool, errStr string) {
-	if params[0] == nil {
-		return false, "Error value is nil"
-	}
-	err, ok := params[0].(error)
-	if !ok {
-		return false, "Value is not an error"
+	err, errStr := errorParam(params[0])
+	if errStr != "" {
+		return false, errStr
 	}
 	params[0] = err.Error()
 	names[0] = "error"
 	return matches(params[0], params[1])
 }
 
+// -----------------------------------------------------------------------
+// ErrorIs checker.
+
+type errorIsChecker struct {
+	*CheckerInfo
+}
+
+// The ErrorIs checker verifies that the error value is non nil and has
+// the target error in its chain of wrapped errors, as errors.Is does.
+//
+// For example:
+//
+//     c.Assert(err, ErrorIs, os.ErrNotExist)
+//
+var ErrorIs Checker = errorIsChecker{
+	&CheckerInfo{Name: "ErrorIs", Params: []string{"value", "target"}},
+}
+
+func (checker errorIsChecker) Check(params []interface{}, names []string) (result bool, errStr string) {
+	err, errStr := errorParam(params[0])
+	if errStr != "" {
+		return false, errStr
+	}
+	target, ok := params[1].(error)
+	if !ok {
+		return false, "Target is not an error"
+	}
+	return errors.Is(err, target), ""
+}
+
+// -----------------------------------------------------------------------
+// ErrorAs checker.
+
+type errorAsChecker struct {
+	*CheckerInfo
+}
+
+// The ErrorAs checker verifies that the error value is non nil and has an
+// error assignable to the value pointed to by target in its chain of
+// wrapped errors, as errors.As does. When it does, target is set to the
+// first such error, so that it may be checked further.
+//
+// For example:
+//
+//     var pathErr *os.PathError
+//     c.Assert(err, ErrorAs, &pathErr)
+//     c.Assert(pathErr.Path, Equals, "/etc/missing")
+//
+var ErrorAs Checker = errorAsChecker{
+	&CheckerInfo{Name: "ErrorAs", Params: []string{"value", "target"}},
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func (checker errorAsChecker) Check(params []interface{}, names []string) (result bool, errStr string) {
+	err, errStr := errorParam(params[0])
+	if errStr != "" {
+		return false, errStr
+	}
+	target := reflect.ValueOf(params[1])
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return false, "Target must be a non-nil pointer"
+	}
+	if elem := target.Type().Elem(); elem.Kind() != reflect.Interface && !elem.Implements(errorType) {
+		return false, fmt.Sprintf("Target points to %s, which doesn't implement error", elem)
+	}
+	return errors.As(err, params[1]), ""
+}
+
+// -----------------------------------------------------------------------
+// ErrorChainMatches checker.
+
+type errorChainMatchesChecker struct {
+	*CheckerInfo
+}
+
+// The ErrorChainMatches checker verifies that the error value is non nil
+// and that the message of some error in its chain of wrapped errors
+// matches the regular expression provided. Every error in the chain is
+// shown, with its type and message, when the check fails.
+//
+// For example:
+//
+//     c.Assert(err, ErrorChainMatches, "connection refused")
+//
+var ErrorChainMatches Checker = errorChainMatchesChecker{
+	&CheckerInfo{Name: "ErrorChainMatches", Params: []string{"value", "regex"}},
+}
+
+func (checker errorChainMatchesChecker) Check(params []interface{}, names []string) (result bool, errStr string) {
+	err, errStr := errorParam(params[0])
+	if errStr != "" {
+		return false, errStr
+	}
+	var chain string
+	walkErrorChain(err, 0, func(err error, depth int) {
+		chain += fmt.Sprintf("%s%T: %q\n", strings.Repeat("  ", depth), err, err.Error())
+		if !result && errStr == "" {
+			result, errStr = matches(err.Error(), params[1])
+		}
+	})
+	params[0] = chain
+	names[0] = "error chain"
+	return result, errStr
+}
+
+// Call visit with err and every error it wraps, at the given depth and
+// below, in the order errors.Is goes through them.
+func walkErrorChain(err error, depth int, visit func(err error, depth int)) {
+	for err != nil {
+		visit(err, depth)
+		switch wrapper := err.(type) {
+		case interface{ Unwrap() error }:
+			err = wrapper.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, wrapped := range wrapper.Unwrap() {
+				walkErrorChain(wrapped, depth+1, visit)
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+func errorParam(value interface{}) (error, string) {
+	if value == nil {
+		return nil, "Error value is nil"
+	}
+	err, ok := value.(error)
+	if !ok {
+		return nil, "Value is not an error"
+	}
+	return err, ""
+}
+
 // -----------------------------------------------------------------------
 // Matches checker.
 
@@ -473,25 +817,10 @@ func commonEquals(container, elem interface{}, result *bool, error *string) bool
 	elemV := reflect.ValueOf(elem)
 	switch containerV.Kind() {
 	case reflect.Slice, reflect.Array, reflect.Map:
-		containerElemType := containerV.Type().Elem()
-		if containerElemType.Kind() == reflect.Interface {
-			// Ensure that element implements the type of elements stored in the container.
-			if !elemV.Type().Implements(containerElemType) {
-				*result = false
-				*error = fmt.Sprintf(""+
-					"container has items of interface type %s but expected"+
-					" element does not implement it", containerElemType)
-				return true
-			}
-		} else {
-			// Ensure that type of elements in container is compatible with elem
-			if containerElemType != elemV.Type() {
-				*result = false
-				*error = fmt.Sprintf(
-					"container has items of type %s but expected element is a %s",
-					containerElemType, elemV.Type())
-				return true
-			}
+		if err := itemTypeError(containerV.Type().Elem(), elemV.Type()); err != "" {
+			*result = false
+			*error = err
+			return true
 		}
 	case reflect.String:
 		// When container is a string, we expect elem to be a string as well
@@ -507,6 +836,27 @@ func commonEquals(container, elem interface{}, result *bool, error *string) bool
 	return false
 }
 
+// Returns why an element of type elemType can't be an item of a container
+// with items of type containerElemType, or an empty string if it can.
+func itemTypeError(containerElemType, elemType reflect.Type) string {
+	if containerElemType.Kind() == reflect.Interface {
+		// Ensure that element implements the type of elements stored in the container.
+		if !elemType.Implements(containerElemType) {
+			return fmt.Sprintf(""+
+				"container has items of interface type %s but expected"+
+				" element does not implement it", containerElemType)
+		}
+	} else {
+		// Ensure that type of elements in container is compatible with elem
+		if containerElemType != elemType {
+			return fmt.Sprintf(
+				"container has items of type %s but expected element is a %s",
+				containerElemType, elemType)
+		}
+	}
+	return ""
+}
+
 func (c *containsChecker) Check(params []interface{}, names []string) (result bool, error string) {
 	defer func() {
 		if v := recover(); v != nil {
@@ -581,3 +931,1150 @@ func (c *deepContainsChecker) Check(params []interface{}, names []string) (resul
 		return false, fmt.Sprintf("%T is not a supported container", container)
 	}
 }
+
+type containerItem struct {
+	label string // Index or key of the item, as "[key]".
+	value interface{}
+}
+
+// Return the items of a slice or array, or the values of a map sorted by
+// their keys, and whether container is one of those.
+func containerItems(container interface{}) ([]containerItem, bool) {
+	var items []containerItem
+	switch containerV := reflect.ValueOf(container); containerV.Kind() {
+	case reflect.Slice, reflect.Array:
+		for length, i := containerV.Len(), 0; i < length; i++ {
+			items = append(items, containerItem{fmt.Sprintf("[%d]", i), containerV.Index(i).Interface()})
+		}
+	case reflect.Map:
+		for _, keyV := range containerV.MapKeys() {
+			items = append(items, containerItem{fmt.Sprintf("[%#v]", keyV.Interface()), containerV.MapIndex(keyV).Interface()})
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].label < items[j].label })
+	default:
+		return nil, false
+	}
+	return items, true
+}
+
+// -----------------------------------------------------------------------
+// Each and Any checkers.
+
+// The Each checker verifies that the provided checker succeeds on every
+// item of the obtained slice or array, or on every value of the obtained
+// map, with the expected arguments of the checker following the obtained
+// container. When the check fails, the items it failed on are reported.
+// With Equals and DeepEquals, even wrapped in Not or combined with other
+// checkers, an expected value of a type the items can't have is an error,
+// as with Contains.
+//
+// For example:
+//
+//     c.Assert(ports, Each(GreaterThan), 1024)
+//
+func Each(checker Checker) Checker {
+	return &itemsChecker{"Each", true, checker}
+}
+
+// The Any checker verifies that the provided checker succeeds on at least
+// one item of the obtained slice or array, or on one value of the obtained
+// map, as with Each. Errors of the checker on an item only count as it
+// not succeeding.
+//
+// For example:
+//
+//     c.Assert(users, Any(Satisfies), func(u *User) bool { return u.Admin })
+//
+func Any(checker Checker) Checker {
+	return &itemsChecker{"Any", false, checker}
+}
+
+type itemsChecker struct {
+	name string
+	all  bool
+	sub  Checker
+}
+
+func (checker *itemsChecker) Info() *CheckerInfo {
+	info := checker.sub.Info()
+	return &CheckerInfo{
+		Name:   checker.name + "(" + info.Name + ")",
+		Params: append([]string{"container"}, info.Params[1:]...),
+	}
+}
+
+func (checker *itemsChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	return explainedResult(checker.checkExplained(params, names))
+}
+
+func (checker *itemsChecker) checkExplained(params []interface{}, names []string) (result bool, explanation, error string) {
+	items, ok := containerItems(params[0])
+	if !ok {
+		return false, "", fmt.Sprintf("%T is not a supported container", params[0])
+	}
+	// Items of the wrong type would never match, as with Contains.
+	containerElemType := reflect.TypeOf(params[0]).Elem()
+	for _, i := range sameTypeParams(checker.sub) {
+		if elem := params[i]; elem != nil {
+			if err := itemTypeError(containerElemType, reflect.TypeOf(elem)); err != "" {
+				return false, "", err
+			}
+		}
+	}
+	info := checker.sub.Info()
+	var unmatched, errs []string
+	for _, item := range items {
+		subParams := append([]interface{}{item.value}, params[1:]...)
+		subNames := append([]string{info.Params[0]}, names[1:]...)
+		subResult, _, subError := checkExplained(checker.sub, subParams, subNames)
+		switch {
+		case subError != "":
+			errs = append(errs, item.label+": "+subError)
+		case !subResult:
+			unmatched = append(unmatched, item.label)
+		case !checker.all:
+			return true, "", ""
+		}
+	}
+	if checker.all {
+		if len(errs) > 0 {
+			return false, "", strings.Join(errs, "; ")
+		}
+		if len(unmatched) > 0 {
+			return false, info.Name + " didn't match " + strings.Join(unmatched, ", "), ""
+		}
+		return true, "", ""
+	}
+	if len(items) == 0 {
+		return false, "Container is empty", ""
+	}
+	return false, strings.Join(append([]string{info.Name + " didn't match any item"}, errs...), "; "), ""
+}
+
+// -----------------------------------------------------------------------
+// SameElements checker.
+
+type sameElementsChecker struct {
+	*CheckerInfo
+}
+
+// The SameElements checker verifies that the obtained slice or array has
+// the same items as the expected one, compared with reflect.DeepEqual, in
+// any order. Items must appear the same number of times in both. When the
+// check fails, the items missing from and the extra items in the obtained
+// value are reported.
+//
+// For example:
+//
+//     c.Assert(names, SameElements, []string{"b", "a", "c"})
+//
+var SameElements Checker = &sameElementsChecker{
+	&CheckerInfo{Name: "SameElements", Params: []string{"obtained", "expected"}},
+}
+
+func (checker *sameElementsChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	return explainedResult(checker.checkExplained(params, names))
+}
+
+func (checker *sameElementsChecker) checkExplained(params []interface{}, names []string) (result bool, explanation, error string) {
+	var lists [2][]interface{}
+	for i, param := range params {
+		switch reflect.ValueOf(param).Kind() {
+		case reflect.Slice, reflect.Array:
+		default:
+			return false, "", fmt.Sprintf("%s is a %T, not a slice or array", names[i], param)
+		}
+		items, _ := containerItems(param)
+		for _, item := range items {
+			lists[i] = append(lists[i], item.value)
+		}
+	}
+	var extra []string
+	missing := lists[1]
+	for _, item := range lists[0] {
+		found := false
+		for i, expected := range missing {
+			if reflect.DeepEqual(item, expected) {
+				missing = append(missing[:i:i], missing[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			extra = append(extra, fmt.Sprintf("%#v", item))
+		}
+	}
+	var problems []string
+	if len(missing) > 0 {
+		var items []string
+		for _, item := range missing {
+			items = append(items, fmt.Sprintf("%#v", item))
+		}
+		problems = append(problems, "Missing: "+strings.Join(items, ", "))
+	}
+	if len(extra) > 0 {
+		problems = append(problems, "Extra: "+strings.Join(extra, ", "))
+	}
+	return len(problems) == 0, strings.Join(problems, "; "), ""
+}
+
+// -----------------------------------------------------------------------
+// HasKey and HasKeys checkers.
+
+type hasKeyChecker struct {
+	*CheckerInfo
+}
+
+// The HasKey checker verifies that the obtained map has the provided key.
+//
+// For example:
+//
+//     c.Assert(headers, HasKey, "Content-Type")
+//
+var HasKey Checker = &hasKeyChecker{
+	&CheckerInfo{Name: "HasKey", Params: []string{"map", "key"}},
+}
+
+func (checker *hasKeyChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	mapV, error := mapParam(params[0], names[0])
+	if error != "" {
+		return false, error
+	}
+	keyV, error := mapKey(mapV, params[1], names[1])
+	if error != "" {
+		return false, error
+	}
+	return mapV.MapIndex(keyV).IsValid(), ""
+}
+
+type hasKeysChecker struct {
+	*CheckerInfo
+}
+
+// The HasKeys checker verifies that the obtained map has all the keys in
+// the provided slice or array, and reports the missing ones otherwise.
+// The map may have other keys too.
+//
+// For example:
+//
+//     c.Assert(config, HasKeys, []string{"host", "port"})
+//
+var HasKeys Checker = &hasKeysChecker{
+	&CheckerInfo{Name: "HasKeys", Params: []string{"map", "keys"}},
+}
+
+func (checker *hasKeysChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	return explainedResult(checker.checkExplained(params, names))
+}
+
+func (checker *hasKeysChecker) checkExplained(params []interface{}, names []string) (result bool, explanation, error string) {
+	mapV, error := mapParam(params[0], names[0])
+	if error != "" {
+		return false, "", error
+	}
+	switch reflect.ValueOf(params[1]).Kind() {
+	case reflect.Slice, reflect.Array:
+	default:
+		return false, "", fmt.Sprintf("%s is a %T, not a slice or array", names[1], params[1])
+	}
+	keys, _ := containerItems(params[1])
+	var missing []string
+	for _, key := range keys {
+		keyV, error := mapKey(mapV, key.value, names[1]+key.label)
+		if error != "" {
+			return false, "", error
+		}
+		if !mapV.MapIndex(keyV).IsValid() {
+			missing = append(missing, fmt.Sprintf("%#v", key.value))
+		}
+	}
+	if len(missing) > 0 {
+		return false, "Missing keys: " + strings.Join(missing, ", "), ""
+	}
+	return true, "", ""
+}
+
+func mapParam(param interface{}, name string) (reflect.Value, string) {
+	mapV := reflect.ValueOf(param)
+	if mapV.Kind() != reflect.Map {
+		return mapV, fmt.Sprintf("%s is a %T, not a map", name, param)
+	}
+	return mapV, ""
+}
+
+// Return key as a value usable to index the map.
+func mapKey(mapV reflect.Value, key interface{}, name string) (reflect.Value, string) {
+	keyType := mapV.Type().Key()
+	keyV := reflect.ValueOf(key)
+	if !keyV.IsValid() {
+		if keyType.Kind() != reflect.Interface {
+			return keyV, fmt.Sprintf("%s is nil, but the map has keys of type %s", name, keyType)
+		}
+		return reflect.Zero(keyType), ""
+	}
+	if !keyV.Type().AssignableTo(keyType) {
+		return keyV, fmt.Sprintf("%s is a %T, but the map has keys of type %s", name, key, keyType)
+	}
+	return keyV, ""
+}
+
+// -----------------------------------------------------------------------
+// Fields checker.
+
+type fieldsChecker struct {
+	*CheckerInfo
+}
+
+// The Fields checker verifies the fields of the obtained struct, or
+// pointer to struct, named in the provided map[string]interface{}. Fields
+// of nested structs are named by their path, such as "Meta.Owner". Each
+// field is compared with DeepEquals to the value in the map, unless that
+// value is a Checker taking no expected arguments, or a checker with its
+// arguments built with Checked. Fields which aren't named are ignored, so
+// the check keeps working as fields are added to the struct. The fields
+// which didn't match are reported.
+//
+// For example:
+//
+//     c.Assert(user, Fields, map[string]interface{}{
+//         "Name":       "alice",
+//         "Meta.Owner": NotNil,
+//         "Age":        Checked(GreaterOrEqual, 18),
+//     })
+//
+var Fields Checker = &fieldsChecker{
+	&CheckerInfo{Name: "Fields", Params: []string{"obtained", "fields"}},
+}
+
+// Checked bundles a checker with its expected arguments, to be used for
+// checking a field with Fields.
+func Checked(checker Checker, args ...interface{}) interface{} {
+	return &checkedValue{checker, args}
+}
+
+type checkedValue struct {
+	checker Checker
+	args    []interface{}
+}
+
+func (checker *fieldsChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	return explainedResult(checker.checkExplained(params, names))
+}
+
+func (checker *fieldsChecker) checkExplained(params []interface{}, names []string) (result bool, explanation, error string) {
+	fields, ok := params[1].(map[string]interface{})
+	if !ok {
+		return false, "", fmt.Sprintf("%s must be a map[string]interface{}", names[1])
+	}
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var mismatches []string
+	for _, path := range paths {
+		value, error := fieldValue(params[0], names[0], path)
+		if error != "" {
+			return false, "", error
+		}
+		var check *checkedValue
+		switch expected := fields[path].(type) {
+		case *checkedValue:
+			check = expected
+		case Checker:
+			check = &checkedValue{expected, nil}
+		default:
+			if !reflect.DeepEqual(value, expected) {
+				mismatches = append(mismatches, fmt.Sprintf("%s is %#v, not %#v", path, value, expected))
+			}
+			continue
+		}
+		info := check.checker.Info()
+		if len(check.args) != len(info.Params)-1 {
+			return false, "", fmt.Sprintf("Wrong number of arguments for %s on %s: want %d, got %d",
+				info.Name, path, len(info.Params)-1, len(check.args))
+		}
+		subParams := append([]interface{}{value}, check.args...)
+		subNames := append([]string{path}, info.Params[1:]...)
+		subResult, subExplanation, subError := checkExplained(check.checker, subParams, subNames)
+		switch {
+		case subError != "":
+			return false, "", path + ": " + subError
+		case !subResult && subExplanation != "":
+			mismatches = append(mismatches, fmt.Sprintf("%s is %#v, %s didn't match: %s", path, value, info.Name, subExplanation))
+		case !subResult:
+			mismatches = append(mismatches, fmt.Sprintf("%s is %#v, %s didn't match", path, value, info.Name))
+		}
+	}
+	if len(mismatches) > 0 {
+		return false, strings.Join(mismatches, "; "), ""
+	}
+	return true, "", ""
+}
+
+// Return the value of the field of obj at the given dot separated path,
+// following pointers to structs along the way.
+func fieldValue(obj interface{}, name, path string) (interface{}, string) {
+	v := reflect.ValueOf(obj)
+	walked := name
+	for _, field := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, fmt.Sprintf("%s is nil", walked)
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil, fmt.Sprintf("%s is not a struct", walked)
+		}
+		sf, ok := v.Type().FieldByName(field)
+		if !ok {
+			return nil, fmt.Sprintf("%s has no field %s", walked, field)
+		}
+		if sf.PkgPath != "" {
+			return nil, fmt.Sprintf("%s.%s is unexported", walked, field)
+		}
+		var err error
+		if v, err = v.FieldByIndexErr(sf.Index); err != nil {
+			return nil, fmt.Sprintf("%s.%s can't be reached: %v", walked, field, err)
+		}
+		walked += "." + field
+	}
+	return v.Interface(), ""
+}
+
+// -----------------------------------------------------------------------
+// Ordering checkers.
+
+type orderChecker struct {
+	*CheckerInfo
+	accept func(cmp int) bool
+}
+
+// The GreaterThan checker verifies that the obtained value is greater
+// than the provided bound. Both values must either be numbers, of any
+// integer or floating point kind including time.Duration, or be time.Time
+// values. Numbers of different kinds are compared by their exact value,
+// but a time.Duration is only compared with another one.
+//
+// For example:
+//
+//     c.Assert(len(queue), GreaterThan, 5)
+//
+var GreaterThan Checker = &orderChecker{
+	&CheckerInfo{Name: "GreaterThan", Params: []string{"obtained", "bound"}},
+	func(cmp int) bool { return cmp > 0 },
+}
+
+// The GreaterOrEqual checker verifies that the obtained value is greater
+// than or equal to the provided bound, compared as done by GreaterThan.
+//
+// For example:
+//
+//     c.Assert(elapsed, GreaterOrEqual, time.Second)
+//
+var GreaterOrEqual Checker = &orderChecker{
+	&CheckerInfo{Name: "GreaterOrEqual", Params: []string{"obtained", "bound"}},
+	func(cmp int) bool { return cmp >= 0 },
+}
+
+// The LessThan checker verifies that the obtained value is less than the
+// provided bound, compared as done by GreaterThan.
+//
+// For example:
+//
+//     c.Assert(ratio, LessThan, 0.5)
+//
+var LessThan Checker = &orderChecker{
+	&CheckerInfo{Name: "LessThan", Params: []string{"obtained", "bound"}},
+	func(cmp int) bool { return cmp < 0 },
+}
+
+// The LessOrEqual checker verifies that the obtained value is less than
+// or equal to the provided bound, compared as done by GreaterThan.
+//
+// For example:
+//
+//     c.Assert(started, LessOrEqual, time.Now())
+//
+var LessOrEqual Checker = &orderChecker{
+	&CheckerInfo{Name: "LessOrEqual", Params: []string{"obtained", "bound"}},
+	func(cmp int) bool { return cmp <= 0 },
+}
+
+func (checker *orderChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	cmp, error := compareOrdered(params[0], params[1], names[0], names[1])
+	if error != "" {
+		return false, error
+	}
+	return checker.accept(cmp), ""
+}
+
+// -----------------------------------------------------------------------
+// Between checker.
+
+type betweenChecker struct {
+	*CheckerInfo
+}
+
+// The Between checker verifies that the obtained value is greater than or
+// equal to min, and less than or equal to max, compared as done by
+// GreaterThan.
+//
+// For example:
+//
+//     c.Assert(port, Between, 1024, 65535)
+//
+var Between Checker = &betweenChecker{
+	&CheckerInfo{Name: "Between", Params: []string{"obtained", "min", "max"}},
+}
+
+func (checker *betweenChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	cmpMin, error := compareOrdered(params[0], params[1], names[0], names[1])
+	if error != "" {
+		return false, error
+	}
+	cmpMax, error := compareOrdered(params[0], params[2], names[0], names[2])
+	if error != "" {
+		return false, error
+	}
+	return cmpMin >= 0 && cmpMax <= 0, ""
+}
+
+// Compare a to b, returning -1, 0 or 1 as a is less than, equal to, or
+// greater than b. The names of the values are used in the error returned
+// when they can't be compared.
+func compareOrdered(a, b interface{}, aName, bName string) (cmp int, error string) {
+	aTime, aIsTime := a.(time.Time)
+	bTime, bIsTime := b.(time.Time)
+	if aIsTime && bIsTime {
+		switch {
+		case aTime.Before(bTime):
+			return -1, ""
+		case aTime.After(bTime):
+			return 1, ""
+		}
+		return 0, ""
+	}
+	aV, bV := reflect.ValueOf(a), reflect.ValueOf(b)
+	aKind, bKind := numberKindOf(aV), numberKindOf(bV)
+	if !aIsTime && aKind == notNumber {
+		return 0, notOrderedError(aName, a)
+	}
+	if !bIsTime && bKind == notNumber {
+		return 0, notOrderedError(bName, b)
+	}
+	_, aIsDuration := a.(time.Duration)
+	_, bIsDuration := b.(time.Duration)
+	if aIsTime || bIsTime || aIsDuration != bIsDuration {
+		return 0, fmt.Sprintf("Can't compare %s of type %T with %s of type %T", aName, a, bName, b)
+	}
+
+	switch {
+	case aKind == floatNumber || bKind == floatNumber:
+		if aKind == floatNumber && math.IsNaN(aV.Float()) || bKind == floatNumber && math.IsNaN(bV.Float()) {
+			return 0, "Can't compare NaN values"
+		}
+		return toBigFloat(aV).Cmp(toBigFloat(bV)), ""
+	case aKind == signedNumber && bKind == signedNumber:
+		return compareInts(aV.Int(), bV.Int()), ""
+	case aKind == signedNumber && aV.Int() < 0:
+		return -1, ""
+	case bKind == signedNumber && bV.Int() < 0:
+		return 1, ""
+	}
+	return compareUints(toUint(aV), toUint(bV)), ""
+}
+
+func notOrderedError(name string, value interface{}) string {
+	return fmt.Sprintf("%s has type %T, which is neither a number nor a time.Time", name, value)
+}
+
+type numberKind int
+
+const (
+	notNumber numberKind = iota
+	signedNumber
+	unsignedNumber
+	floatNumber
+)
+
+func numberKindOf(v reflect.Value) numberKind {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return signedNumber
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return unsignedNumber
+	case reflect.Float32, reflect.Float64:
+		return floatNumber
+	}
+	return notNumber
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch numberKindOf(v) {
+	case signedNumber:
+		return float64(v.Int())
+	case unsignedNumber:
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+// Return the number held by v exactly, so that integers too large for a
+// float64 are compared with floats by their actual value.
+func toBigFloat(v reflect.Value) *big.Float {
+	switch numberKindOf(v) {
+	case signedNumber:
+		return new(big.Float).SetInt64(v.Int())
+	case unsignedNumber:
+		return new(big.Float).SetUint64(v.Uint())
+	}
+	return big.NewFloat(v.Float())
+}
+
+func toUint(v reflect.Value) uint64 {
+	if numberKindOf(v) == signedNumber {
+		return uint64(v.Int())
+	}
+	return v.Uint()
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// -----------------------------------------------------------------------
+// FloatEquals checker.
+
+type floatEqualsChecker struct {
+	*CheckerInfo
+	relative bool
+}
+
+// The FloatEquals checker verifies that the obtained number is equal to the
+// expected one within the provided absolute tolerance. NaN is only equal
+// to NaN, and infinities only to themselves.
+//
+// For example:
+//
+//     c.Assert(math.Sqrt(2)*math.Sqrt(2), FloatEquals, 2.0, 1e-9)
+//
+var FloatEquals Checker = &floatEqualsChecker{
+	&CheckerInfo{Name: "FloatEquals", Params: []string{"obtained", "expected", "epsilon"}},
+	false,
+}
+
+// The FloatRelEquals checker verifies that the obtained number is equal to
+// the expected one within the provided tolerance, relative to the larger
+// of the two, as in 0.01 for 1%. NaN and infinities are compared as done
+// by FloatEquals.
+//
+// For example:
+//
+//     c.Assert(throughput, FloatRelEquals, 1e6, 0.05)
+//
+var FloatRelEquals Checker = &floatEqualsChecker{
+	&CheckerInfo{Name: "FloatRelEquals", Params: []string{"obtained", "expected", "tolerance"}},
+	true,
+}
+
+func (checker *floatEqualsChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	var values [3]float64
+	for i, param := range params {
+		v := reflect.ValueOf(param)
+		if numberKindOf(v) == notNumber {
+			return false, fmt.Sprintf("%s has type %T, which isn't a number", names[i], param)
+		}
+		values[i] = toFloat(v)
+	}
+	obtained, expected, tolerance := values[0], values[1], values[2]
+	if math.IsNaN(tolerance) || tolerance < 0 {
+		return false, names[2] + " must not be negative"
+	}
+	if math.IsNaN(obtained) || math.IsNaN(expected) {
+		return math.IsNaN(obtained) && math.IsNaN(expected), ""
+	}
+	if obtained == expected || math.IsInf(obtained, 0) || math.IsInf(expected, 0) {
+		return obtained == expected, ""
+	}
+	diff := math.Abs(obtained - expected)
+	if checker.relative {
+		return diff <= tolerance*math.Max(math.Abs(obtained), math.Abs(expected)), ""
+	}
+	return diff <= tolerance, ""
+}
+
+// -----------------------------------------------------------------------
+// TimeEquals checker.
+
+type timeEqualsChecker struct {
+	*CheckerInfo
+}
+
+// The TimeEquals checker verifies that the obtained time is the same
+// instant as the expected one, like time.Time.Equal does, regardless of
+// their locations and monotonic clock readings which make DeepEquals
+// and Equals fail on equal times.
+//
+// For example:
+//
+//     c.Assert(parsed, TimeEquals, stored.UTC())
+//
+var TimeEquals Checker = &timeEqualsChecker{
+	&CheckerInfo{Name: "TimeEquals", Params: []string{"obtained", "expected"}},
+}
+
+func (checker *timeEqualsChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	times, error := timeParams(params[:2], names)
+	if error != "" {
+		return false, error
+	}
+	return times[0].Equal(times[1]), ""
+}
+
+// -----------------------------------------------------------------------
+// WithinDuration checker.
+
+type withinDurationChecker struct {
+	*CheckerInfo
+}
+
+// The WithinDuration checker verifies that the obtained time is at most
+// delta before or after the expected time.
+//
+// For example:
+//
+//     c.Assert(file.ModTime(), WithinDuration, time.Now(), time.Minute)
+//
+var WithinDuration Checker = &withinDurationChecker{
+	&CheckerInfo{Name: "WithinDuration", Params: []string{"obtained", "expected", "delta"}},
+}
+
+func (checker *withinDurationChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	times, error := timeParams(params[:2], names)
+	if error != "" {
+		return false, error
+	}
+	delta, ok := params[2].(time.Duration)
+	if !ok {
+		return false, fmt.Sprintf("%s must be a time.Duration", names[2])
+	}
+	diff := times[0].Sub(times[1])
+	return -delta <= diff && diff <= delta, ""
+}
+
+func timeParams(params []interface{}, names []string) ([]time.Time, string) {
+	times := make([]time.Time, len(params))
+	for i, param := range params {
+		t, ok := param.(time.Time)
+		if !ok {
+			return nil, fmt.Sprintf("%s has type %T, which isn't a time.Time", names[i], param)
+		}
+		times[i] = t
+	}
+	return times, ""
+}
+
+// -----------------------------------------------------------------------
+// Satisfies checker.
+
+type satisfiesChecker struct {
+	*CheckerInfo
+}
+
+// The Satisfies checker verifies that the obtained value satisfies the
+// provided predicate, which must be a function taking a single argument
+// the obtained value can be assigned to, and returning either a bool
+// telling whether the value is fine, or an error telling why it isn't.
+//
+// For example:
+//
+//     c.Assert(name, Satisfies, func(s string) bool { return s != "" })
+//     c.Assert(config, Satisfies, (*Config).Validate)
+//
+var Satisfies Checker = &satisfiesChecker{
+	&CheckerInfo{Name: "Satisfies", Params: []string{"obtained", "predicate"}},
+}
+
+func (checker *satisfiesChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	return explainedResult(checker.checkExplained(params, names))
+}
+
+func (checker *satisfiesChecker) checkExplained(params []interface{}, names []string) (result bool, explanation, errStr string) {
+	predicate := reflect.ValueOf(params[1])
+	if predicate.Kind() != reflect.Func || predicate.IsNil() {
+		return false, "", "predicate must be a func(T) bool or func(T) error"
+	}
+	t := predicate.Type()
+	if t.NumIn() != 1 || t.IsVariadic() || t.NumOut() != 1 ||
+		(t.Out(0).Kind() != reflect.Bool && t.Out(0) != errorType) {
+		return false, "", fmt.Sprintf("predicate must be a func(T) bool or func(T) error, not %s", t)
+	}
+	arg := reflect.ValueOf(params[0])
+	if !arg.IsValid() {
+		switch t.In(0).Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			arg = reflect.Zero(t.In(0))
+		default:
+			return false, "", fmt.Sprintf("nil can't be passed to predicate of type %s", t)
+		}
+	} else if !arg.Type().AssignableTo(t.In(0)) {
+		return false, "", fmt.Sprintf("%s of type %T can't be passed to predicate of type %s", names[0], params[0], t)
+	}
+	out := predicate.Call([]reflect.Value{arg})[0]
+	if out.Kind() == reflect.Bool {
+		return out.Bool(), "", ""
+	}
+	if out.IsNil() {
+		return true, "", ""
+	}
+	return false, out.Interface().(error).Error(), ""
+}
+
+// -----------------------------------------------------------------------
+// JSONEquals checker.
+
+type jsonEqualsChecker struct {
+	*CheckerInfo
+}
+
+// The JSONEquals checker verifies that the obtained JSON document is
+// equal to the expected one, regardless of whitespace, of the order of
+// object keys, and of how numbers are written. Values given as a string
+// or []byte are parsed as JSON, and any other value is marshalled first.
+// The paths to the values which differ are reported.
+//
+// For example:
+//
+//     c.Assert(body, JSONEquals, `{"id": 1, "tags": ["a", "b"]}`)
+//
+var JSONEquals Checker = &jsonEqualsChecker{
+	&CheckerInfo{Name: "JSONEquals", Params: []string{"obtained", "expected"}},
+}
+
+func (checker *jsonEqualsChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	return explainedResult(checker.checkExplained(params, names))
+}
+
+func (checker *jsonEqualsChecker) checkExplained(params []interface{}, names []string) (result bool, explanation, error string) {
+	var values [2]interface{}
+	for i, param := range params {
+		if values[i], error = parseJSON(param, names[i]); error != "" {
+			return false, "", error
+		}
+	}
+	diffs := jsonDiff("$", values[0], values[1])
+	return len(diffs) == 0, strings.Join(diffs, "; "), ""
+}
+
+func parseJSON(param interface{}, name string) (interface{}, string) {
+	var data []byte
+	switch param := param.(type) {
+	case string:
+		data = []byte(param)
+	case []byte:
+		data = param
+	case json.RawMessage:
+		data = param
+	default:
+		var err error
+		if data, err = json.Marshal(param); err != nil {
+			return nil, fmt.Sprintf("%s can't be marshalled as JSON: %v", name, err)
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err == nil {
+		if _, err = decoder.Token(); err == io.EOF {
+			return value, ""
+		} else if err == nil {
+			err = errors.New("unexpected data after the top-level value")
+		}
+	}
+	return nil, fmt.Sprintf("%s isn't valid JSON: %v", name, err)
+}
+
+// Return the differences between the obtained and expected JSON values,
+// found under the given path.
+func jsonDiff(path string, obtained, expected interface{}) []string {
+	switch obtained := obtained.(type) {
+	case map[string]interface{}:
+		expected, ok := expected.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(obtained)+len(expected))
+		for key := range obtained {
+			keys = append(keys, key)
+		}
+		for key := range expected {
+			if _, ok := obtained[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		var diffs []string
+		for _, key := range keys {
+			keyPath := jsonKeyPath(path, key)
+			obtainedValue, inObtained := obtained[key]
+			expectedValue, inExpected := expected[key]
+			switch {
+			case !inObtained:
+				diffs = append(diffs, fmt.Sprintf("%s: missing, expected %s", keyPath, jsonString(expectedValue)))
+			case !inExpected:
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected %s", keyPath, jsonString(obtainedValue)))
+			default:
+				diffs = append(diffs, jsonDiff(keyPath, obtainedValue, expectedValue)...)
+			}
+		}
+		return diffs
+	case []interface{}:
+		expected, ok := expected.([]interface{})
+		if !ok {
+			break
+		}
+		var diffs []string
+		for i := 0; i < len(obtained) || i < len(expected); i++ {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(obtained):
+				diffs = append(diffs, fmt.Sprintf("%s: missing, expected %s", itemPath, jsonString(expected[i])))
+			case i >= len(expected):
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected %s", itemPath, jsonString(obtained[i])))
+			default:
+				diffs = append(diffs, jsonDiff(itemPath, obtained[i], expected[i])...)
+			}
+		}
+		return diffs
+	case json.Number:
+		if expected, ok := expected.(json.Number); ok && jsonNumbersEqual(obtained, expected) {
+			return nil
+		}
+	}
+	if reflect.DeepEqual(obtained, expected) {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: obtained %s, expected %s", path, jsonString(obtained), jsonString(expected))}
+}
+
+var jsonIdentifier = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
+
+func jsonKeyPath(path, key string) string {
+	if jsonIdentifier.MatchString(key) {
+		return path + "." + key
+	}
+	return fmt.Sprintf("%s[%q]", path, key)
+}
+
+func jsonNumbersEqual(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+	// Compare exactly, so that large integers aren't rounded.
+	aR, aOK := new(big.Rat).SetString(string(a))
+	bR, bOK := new(big.Rat).SetString(string(b))
+	return aOK && bOK && aR.Cmp(bR) == 0
+}
+
+func jsonString(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%#v", value)
+	}
+	return string(data)
+}
+
+// -----------------------------------------------------------------------
+// XMLEquals checker.
+
+type xmlEqualsChecker struct {
+	*CheckerInfo
+}
+
+// The XMLEquals checker verifies that the obtained XML document is equal
+// to the expected one, with the same elements in the same order, the same
+// attributes in any order, and the same text once leading and trailing
+// whitespace is removed. Comments, processing instructions and namespace
+// prefixes are ignored, but not the namespaces themselves. Values given as
+// a string or []byte are parsed as XML, and any other value is marshalled
+// first. The paths to the elements which differ are reported.
+//
+// For example:
+//
+//     c.Assert(feed, XMLEquals, `<feed><entry id="1"/></feed>`)
+//
+var XMLEquals Checker = &xmlEqualsChecker{
+	&CheckerInfo{Name: "XMLEquals", Params: []string{"obtained", "expected"}},
+}
+
+func (checker *xmlEqualsChecker) Check(params []interface{}, names []string) (result bool, error string) {
+	return explainedResult(checker.checkExplained(params, names))
+}
+
+func (checker *xmlEqualsChecker) checkExplained(params []interface{}, names []string) (result bool, explanation, error string) {
+	var roots [2]*xmlNode
+	for i, param := range params {
+		if roots[i], error = parseXML(param, names[i]); error != "" {
+			return false, "", error
+		}
+	}
+	if roots[0].name != roots[1].name {
+		return false, fmt.Sprintf("/: obtained element <%s>, expected <%s>", roots[0].name, roots[1].name), ""
+	}
+	diffs := xmlDiff("/"+roots[0].name, roots[0], roots[1])
+	return len(diffs) == 0, strings.Join(diffs, "; "), ""
+}
+
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*xmlNode
+}
+
+// Return the name of an element or attribute, qualified by its namespace
+// rather than by the prefix it had.
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
+
+func parseXML(param interface{}, name string) (*xmlNode, string) {
+	var data []byte
+	switch param := param.(type) {
+	case string:
+		data = []byte(param)
+	case []byte:
+		data = param
+	default:
+		var err error
+		if data, err = xml.Marshal(param); err != nil {
+			return nil, fmt.Sprintf("%s can't be marshalled as XML: %v", name, err)
+		}
+	}
+	var root *xmlNode
+	var open []*xmlNode
+	var text []*strings.Builder
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Sprintf("%s isn't valid XML: %v", name, err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: xmlName(token.Name), attrs: make(map[string]string)}
+			for _, attr := range token.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					continue
+				}
+				node.attrs[xmlName(attr.Name)] = attr.Value
+			}
+			if len(open) > 0 {
+				parent := open[len(open)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			} else {
+				return nil, fmt.Sprintf("%s isn't valid XML: more than one root element", name)
+			}
+			open = append(open, node)
+			text = append(text, &strings.Builder{})
+		case xml.EndElement:
+			open[len(open)-1].text = strings.TrimSpace(text[len(text)-1].String())
+			open, text = open[:len(open)-1], text[:len(text)-1]
+		case xml.CharData:
+			if len(text) > 0 {
+				text[len(text)-1].Write(token)
+			} else if len(bytes.TrimSpace(token)) > 0 {
+				return nil, fmt.Sprintf("%s isn't valid XML: text outside of the root element", name)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Sprintf("%s isn't valid XML: no root element", name)
+	}
+	return root, ""
+}
+
+// Return the differences between the obtained and expected elements, which
+// have the same name and are found at the given path.
+func xmlDiff(path string, obtained, expected *xmlNode) []string {
+	var diffs []string
+	attrs := make([]string, 0, len(obtained.attrs)+len(expected.attrs))
+	for attr := range obtained.attrs {
+		attrs = append(attrs, attr)
+	}
+	for attr := range expected.attrs {
+		if _, ok := obtained.attrs[attr]; !ok {
+			attrs = append(attrs, attr)
+		}
+	}
+	sort.Strings(attrs)
+	for _, attr := range attrs {
+		obtainedValue, inObtained := obtained.attrs[attr]
+		expectedValue, inExpected := expected.attrs[attr]
+		switch {
+		case !inObtained:
+			diffs = append(diffs, fmt.Sprintf("%s/@%s: missing, expected %q", path, attr, expectedValue))
+		case !inExpected:
+			diffs = append(diffs, fmt.Sprintf("%s/@%s: unexpected %q", path, attr, obtainedValue))
+		case obtainedValue != expectedValue:
+			diffs = append(diffs, fmt.Sprintf("%s/@%s: obtained %q, expected %q", path, attr, obtainedValue, expectedValue))
+		}
+	}
+	if obtained.text != expected.text {
+		diffs = append(diffs, fmt.Sprintf("%s: obtained text %q, expected %q", path, obtained.text, expected.text))
+	}
+	obtainedPaths := xmlChildPaths(path, obtained.children)
+	expectedPaths := xmlChildPaths(path, expected.children)
+	for i := 0; i < len(obtained.children) || i < len(expected.children); i++ {
+		switch {
+		case i >= len(obtained.children):
+			diffs = append(diffs, fmt.Sprintf("%s: missing", expectedPaths[i]))
+		case i >= len(expected.children):
+			diffs = append(diffs, fmt.Sprintf("%s: unexpected", obtainedPaths[i]))
+		case obtained.children[i].name != expected.children[i].name:
+			diffs = append(diffs, fmt.Sprintf("%s: obtained element <%s>, expected <%s>",
+				obtainedPaths[i], obtained.children[i].name, expected.children[i].name))
+		default:
+			diffs = append(diffs, xmlDiff(obtainedPaths[i], obtained.children[i], expected.children[i])...)
+		}
+	}
+	return diffs
+}
+
+// Return the paths to the given children, with the position of each among
+// the children of the same name, as in "/feed/entry[2]".
+func xmlChildPaths(path string, children []*xmlNode) []string {
+	paths := make([]string, len(children))
+	seen := make(map[string]int)
+	for i, child := range children {
+		seen[child.name]++
+		paths[i] = fmt.Sprintf("%s/%s[%d]", path, child.name, seen[child.name])
+	}
+	return paths
+}
@@ -1,9 +1,14 @@
 package check_test
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"math"
 	"reflect"
 	"runtime"
+	"time"
 
 	"github.com/elopio/check"
 )
@@ -80,6 +85,89 @@ func (s *CheckersS) TestNot(c *check.C) {
 	testCheck(c, check.Not(check.IsNil), true, "", "a")
 }
 
+func (s *CheckersS) TestAllOf(c *check.C) {
+	checker := check.AllOf(check.GreaterThan, check.Not(check.Equals), check.NotNil)
+	testInfo(c, checker, "AllOf(GreaterThan, Not(Equals), NotNil)", []string{"obtained", "bound", "expected"})
+
+	testCheck(c, checker, true, "", 5, 1, 4)
+	testCheck(c, checker, false, "GreaterThan didn't match", 0, 1, 4)
+	testCheck(c, checker, false, "GreaterThan didn't match; Not(Equals) didn't match", 0, 1, 0)
+	testCheck(c, checker, false, "GreaterThan: obtained has type string, which is neither a number nor a time.Time",
+		"a", 1, 4)
+
+	// Explanations are dropped when inverted, but errors aren't.
+	testCheck(c, check.Not(checker), true, "", 0, 1, 4)
+	testCheck(c, check.Not(checker), false, "", 5, 1, 4)
+	testCheck(c, check.Not(checker), true, "GreaterThan: obtained has type string, which is neither a number nor a time.Time",
+		"a", 1, 4)
+
+	c.Check(func() { check.AllOf() }, check.PanicMatches, "AllOf called without checkers")
+}
+
+func (s *CheckersS) TestAnyOf(c *check.C) {
+	checker := check.AnyOf(check.IsNil, check.ErrorMatches)
+	testInfo(c, checker, "AnyOf(IsNil, ErrorMatches)", []string{"value", "regex"})
+
+	testCheck(c, checker, true, "", nil, "some.*")
+	testCheck(c, checker, true, "", errors.New("some error"), "some.*")
+	testCheck(c, checker, false, "IsNil didn't match; ErrorMatches didn't match",
+		errors.New("other error"), "some.*")
+	testCheck(c, checker, false, "IsNil didn't match; ErrorMatches: Value is not an error", 1, "some.*")
+	testCheck(c, check.Not(checker), true, "", errors.New("other error"), "some.*")
+
+	// Expected values changed for reporting by a checker are kept.
+	params, names := testCheck(c, check.AnyOf(check.Equals, check.ErrorChainMatches), false,
+		"Equals didn't match; ErrorChainMatches didn't match", errMissing, nil, "other")
+	c.Check(params, check.DeepEquals, []interface{}{errMissing, nil, "other"})
+	c.Check(names, check.DeepEquals, []string{"obtained", "expected", "regex"})
+
+	// Combinators may be nested.
+	nested := check.AnyOf(check.Equals, check.AllOf(check.GreaterThan, check.LessThan))
+	testCheck(c, nested, true, "", 0, 0, 1, 10)
+	testCheck(c, nested, true, "", 5, 0, 1, 10)
+	testCheck(c, nested, false, "Equals didn't match; AllOf(GreaterThan, LessThan) didn't match: LessThan didn't match",
+		15, 0, 1, 10)
+}
+
+func (s *CheckersS) TestAndOr(c *check.C) {
+	and := check.And(check.GreaterOrEqual, check.LessThan)
+	testInfo(c, and, "And(GreaterOrEqual, LessThan)", []string{"obtained", "bound", "bound"})
+	testCheck(c, and, true, "", 0, 0, 3)
+	testCheck(c, and, false, "LessThan didn't match", 3, 0, 3)
+
+	or := check.Or(check.Equals, check.Equals)
+	testInfo(c, or, "Or(Equals, Equals)", []string{"obtained", "expected", "expected"})
+	testCheck(c, or, true, "", "stopped", "running", "stopped")
+	testCheck(c, or, false, "Equals didn't match; Equals didn't match", "paused", "running", "stopped")
+}
+
+func (s *CheckersS) TestSatisfies(c *check.C) {
+	testInfo(c, check.Satisfies, "Satisfies", []string{"obtained", "predicate"})
+
+	even := func(n int) bool { return n%2 == 0 }
+	testCheck(c, check.Satisfies, true, "", 2, even)
+	testCheck(c, check.Satisfies, false, "", 3, even)
+
+	positive := func(n int) error {
+		if n <= 0 {
+			return fmt.Errorf("%d is not positive", n)
+		}
+		return nil
+	}
+	testCheck(c, check.Satisfies, true, "", 1, positive)
+	testCheck(c, check.Satisfies, false, "-1 is not positive", -1, positive)
+	testCheck(c, check.Not(check.Satisfies), true, "", -1, positive)
+
+	testCheck(c, check.Satisfies, true, "", errMissing, func(err error) bool { return err != nil })
+	testCheck(c, check.Satisfies, true, "", nil, func(err error) bool { return err == nil })
+	testCheck(c, check.Satisfies, false, "nil can't be passed to predicate of type func(int) bool", nil, even)
+	testCheck(c, check.Satisfies, false, "obtained of type string can't be passed to predicate of type func(int) bool",
+		"2", even)
+	testCheck(c, check.Satisfies, false, "predicate must be a func(T) bool or func(T) error", 2, nil)
+	testCheck(c, check.Satisfies, false, "predicate must be a func(T) bool or func(T) error, not func(int) int",
+		2, func(n int) int { return n })
+}
+
 type simpleStruct struct {
 	i int
 }
@@ -160,6 +248,81 @@ func (s *CheckersS) TestErrorMatches(c *check.C) {
 	c.Assert(names[0], check.Equals, "error")
 }
 
+type pathError struct {
+	path string
+	err  error
+}
+
+func (e *pathError) Error() string { return e.path + ": " + e.err.Error() }
+func (e *pathError) Unwrap() error { return e.err }
+
+var errMissing = errors.New("missing")
+
+func (s *CheckersS) TestErrorIs(c *check.C) {
+	testInfo(c, check.ErrorIs, "ErrorIs", []string{"value", "target"})
+
+	wrapped := fmt.Errorf("reading config: %w", &pathError{"/etc/x", errMissing})
+	testCheck(c, check.ErrorIs, true, "", errMissing, errMissing)
+	testCheck(c, check.ErrorIs, true, "", wrapped, errMissing)
+	testCheck(c, check.ErrorIs, true, "", errors.Join(errors.New("other"), wrapped), errMissing)
+	testCheck(c, check.ErrorIs, false, "", wrapped, errors.New("missing"))
+	testCheck(c, check.ErrorIs, false, "Error value is nil", nil, errMissing)
+	testCheck(c, check.ErrorIs, false, "Value is not an error", 1, errMissing)
+	testCheck(c, check.ErrorIs, false, "Target is not an error", wrapped, "missing")
+}
+
+func (s *CheckersS) TestErrorAs(c *check.C) {
+	testInfo(c, check.ErrorAs, "ErrorAs", []string{"value", "target"})
+
+	wrapped := fmt.Errorf("reading config: %w", &pathError{"/etc/x", errMissing})
+	var target *pathError
+	testCheck(c, check.ErrorAs, true, "", wrapped, &target)
+	c.Assert(target, check.NotNil)
+	c.Check(target.path, check.Equals, "/etc/x")
+
+	var iface interface{ Unwrap() error }
+	testCheck(c, check.ErrorAs, true, "", wrapped, &iface)
+	c.Check(iface, check.Equals, error(wrapped))
+
+	target = nil
+	testCheck(c, check.ErrorAs, false, "", errMissing, &target)
+	c.Check(target, check.IsNil)
+
+	testCheck(c, check.ErrorAs, false, "Error value is nil", nil, &target)
+	testCheck(c, check.ErrorAs, false, "Target must be a non-nil pointer", wrapped, target)
+	testCheck(c, check.ErrorAs, false, "Target must be a non-nil pointer", wrapped, (**pathError)(nil))
+	testCheck(c, check.ErrorAs, false, "Target points to string, which doesn't implement error", wrapped, new(string))
+}
+
+func (s *CheckersS) TestErrorChainMatches(c *check.C) {
+	testInfo(c, check.ErrorChainMatches, "ErrorChainMatches", []string{"value", "regex"})
+
+	wrapped := fmt.Errorf("reading config: %w", &pathError{"/etc/x", errMissing})
+	testCheck(c, check.ErrorChainMatches, true, "", wrapped, "missing")
+	testCheck(c, check.ErrorChainMatches, true, "", wrapped, "/etc/x: .*")
+	testCheck(c, check.ErrorChainMatches, true, "", wrapped, "reading config: .*")
+	testCheck(c, check.ErrorChainMatches, false, "Error value is nil", nil, "missing")
+	testCheck(c, check.ErrorChainMatches, false, "Regex must be a string", wrapped, 1)
+
+	// Verify params mutation
+	params, names := testCheck(c, check.ErrorChainMatches, false, "", wrapped, "other")
+	c.Check(params[0], check.Equals, ""+
+		"*fmt.wrapError: \"reading config: /etc/x: missing\"\n"+
+		"*check_test.pathError: \"/etc/x: missing\"\n"+
+		"*errors.errorString: \"missing\"\n")
+	c.Check(names[0], check.Equals, "error chain")
+
+	joined := errors.Join(errMissing, wrapped)
+	testCheck(c, check.ErrorChainMatches, true, "", joined, "/etc/x: missing")
+	params, _ = testCheck(c, check.ErrorChainMatches, false, "", joined, "other")
+	c.Check(params[0], check.Equals, ""+
+		"*errors.joinError: \"missing\\nreading config: /etc/x: missing\"\n"+
+		"  *errors.errorString: \"missing\"\n"+
+		"  *fmt.wrapError: \"reading config: /etc/x: missing\"\n"+
+		"  *check_test.pathError: \"/etc/x: missing\"\n"+
+		"  *errors.errorString: \"missing\"\n")
+}
+
 func (s *CheckersS) TestMatches(c *check.C) {
 	testInfo(c, check.Matches, "Matches", []string{"value", "regex"})
 
@@ -458,3 +621,396 @@ func (s *CheckersS) TestDeepContainsUncomparableType(c *check.C) {
 	testCheck(c, check.DeepContains, true, "", containerSlice, elem)
 	testCheck(c, check.DeepContains, true, "", containerMap, elem)
 }
+
+func (s *CheckersS) TestGreaterThan(c *check.C) {
+	testInfo(c, check.GreaterThan, "GreaterThan", []string{"obtained", "bound"})
+
+	testCheck(c, check.GreaterThan, true, "", 2, 1)
+	testCheck(c, check.GreaterThan, false, "", 1, 1)
+	testCheck(c, check.GreaterThan, false, "", 0, 1)
+	testCheck(c, check.GreaterThan, true, "", uint8(2), uint8(1))
+	testCheck(c, check.GreaterThan, true, "", 1.5, 1.0)
+	testCheck(c, check.GreaterThan, true, "", int64(math.MaxInt64), int64(math.MaxInt64-1))
+	testCheck(c, check.GreaterThan, true, "", uint64(math.MaxUint64), uint64(math.MaxUint64-1))
+
+	// Plain ints, as untyped constants are, may be compared with other
+	// unnamed integer types.
+	testCheck(c, check.GreaterThan, true, "", int64(2), 1)
+	testCheck(c, check.GreaterThan, false, "", -1, uint(0))
+	testCheck(c, check.GreaterThan, true, "", uint(0), -1)
+	testCheck(c, check.GreaterThan, true, "", uint64(math.MaxUint64), math.MaxInt64)
+	testCheck(c, check.GreaterThan, true, "", 2*time.Second, time.Second)
+	testCheck(c, check.GreaterThan, true, "", time.Unix(2, 0), time.Unix(1, 0))
+	testCheck(c, check.GreaterThan, false, "", time.Unix(1, 0), time.Unix(1, 0))
+}
+
+func (s *CheckersS) TestGreaterOrEqual(c *check.C) {
+	testInfo(c, check.GreaterOrEqual, "GreaterOrEqual", []string{"obtained", "bound"})
+
+	testCheck(c, check.GreaterOrEqual, true, "", 2, 1)
+	testCheck(c, check.GreaterOrEqual, true, "", 1, 1)
+	testCheck(c, check.GreaterOrEqual, false, "", 0, 1)
+	testCheck(c, check.GreaterOrEqual, true, "", float32(1), float32(1))
+	testCheck(c, check.GreaterOrEqual, true, "", time.Unix(1, 0), time.Unix(1, 0))
+}
+
+func (s *CheckersS) TestLessThan(c *check.C) {
+	testInfo(c, check.LessThan, "LessThan", []string{"obtained", "bound"})
+
+	testCheck(c, check.LessThan, true, "", 0, 1)
+	testCheck(c, check.LessThan, false, "", 1, 1)
+	testCheck(c, check.LessThan, false, "", 2, 1)
+	testCheck(c, check.LessThan, true, "", int32(-5), 0)
+	testCheck(c, check.LessThan, true, "", 0.25, 0.5)
+	testCheck(c, check.LessThan, true, "", time.Unix(1, 0), time.Unix(2, 0))
+}
+
+func (s *CheckersS) TestLessOrEqual(c *check.C) {
+	testInfo(c, check.LessOrEqual, "LessOrEqual", []string{"obtained", "bound"})
+
+	testCheck(c, check.LessOrEqual, true, "", 0, 1)
+	testCheck(c, check.LessOrEqual, true, "", 1, 1)
+	testCheck(c, check.LessOrEqual, false, "", 2, 1)
+	testCheck(c, check.LessOrEqual, true, "", time.Millisecond, time.Millisecond)
+}
+
+func (s *CheckersS) TestBetween(c *check.C) {
+	testInfo(c, check.Between, "Between", []string{"obtained", "min", "max"})
+
+	testCheck(c, check.Between, true, "", 5, 1, 10)
+	testCheck(c, check.Between, true, "", 1, 1, 10)
+	testCheck(c, check.Between, true, "", 10, 1, 10)
+	testCheck(c, check.Between, false, "", 0, 1, 10)
+	testCheck(c, check.Between, false, "", 11, 1, 10)
+	testCheck(c, check.Between, true, "", 0.5, 0.0, 1.0)
+	testCheck(c, check.Between, true, "", time.Unix(5, 0), time.Unix(1, 0), time.Unix(10, 0))
+	testCheck(c, check.Between, false,
+		"obtained has type string, which is neither a number nor a time.Time", "a", 1, 10)
+}
+
+func (s *CheckersS) TestOrderMismatchedKinds(c *check.C) {
+	testCheck(c, check.GreaterThan, false,
+		"obtained has type string, which is neither a number nor a time.Time", "b", "a")
+	testCheck(c, check.GreaterThan, false,
+		"bound has type <nil>, which is neither a number nor a time.Time", 1, nil)
+	testCheck(c, check.GreaterThan, false,
+		"Can't compare obtained of type time.Time with bound of type int", time.Unix(1, 0), 0)
+	testCheck(c, check.LessThan, false,
+		"Can't compare obtained of type time.Duration with bound of type time.Time", time.Second, time.Unix(1, 0))
+	testCheck(c, check.Between, false,
+		"Can't compare obtained of type int with max of type time.Time", 1, 0, time.Unix(1, 0))
+	testCheck(c, check.GreaterOrEqual, false, "Can't compare NaN values", math.NaN(), 0.0)
+
+	// A time.Duration is only compared with another one.
+	testCheck(c, check.GreaterThan, false,
+		"Can't compare obtained of type time.Duration with bound of type int", 2*time.Second, 5)
+	testCheck(c, check.GreaterThan, false,
+		"Can't compare obtained of type int64 with bound of type time.Duration", int64(5), time.Second)
+	testCheck(c, check.GreaterThan, true, "", 2*time.Second, time.Second)
+
+	// Numbers of different kinds are compared by their exact value.
+	type port uint16
+	testCheck(c, check.GreaterThan, true, "", port(8080), 1024)
+	testCheck(c, check.GreaterThan, true, "", 2, 1.5)
+	testCheck(c, check.GreaterThan, true, "", 1.5, int64(1))
+	testCheck(c, check.GreaterThan, true, "", 0.25, 0)
+	testCheck(c, check.GreaterThan, true, "", int64(1<<53+1), float64(1<<53))
+	testCheck(c, check.GreaterThan, false, "", uint64(1<<63), float64(1<<63))
+	testCheck(c, check.GreaterThan, true, "", uint8(2), uint64(1))
+	testCheck(c, check.GreaterThan, true, "", float32(2), 1.0)
+	testCheck(c, check.LessThan, true, "", -1, uint64(0))
+	testCheck(c, check.LessThan, true, "", math.Inf(-1), int64(math.MinInt64))
+	testCheck(c, check.Between, true, "", 0.5, 0, 1)
+	testCheck(c, check.GreaterOrEqual, false, "Can't compare NaN values", 1, math.NaN())
+}
+
+func (s *CheckersS) TestFloatEquals(c *check.C) {
+	testInfo(c, check.FloatEquals, "FloatEquals", []string{"obtained", "expected", "epsilon"})
+
+	testCheck(c, check.FloatEquals, true, "", 1.0, 1.0, 0.0)
+	testCheck(c, check.FloatEquals, true, "", 1.0, 1.05, 0.1)
+	testCheck(c, check.FloatEquals, false, "", 1.0, 1.2, 0.1)
+	testCheck(c, check.FloatEquals, true, "", 0.1+0.2, 0.3, 1e-9)
+	testCheck(c, check.FloatEquals, true, "", float32(0.1), 0.1, 1e-6)
+	testCheck(c, check.FloatEquals, true, "", 3, 3.0000001, 1e-6)
+
+	// The tolerance is only absolute.
+	testCheck(c, check.FloatEquals, false, "", 100.0, 140.0, 0.5)
+	testCheck(c, check.FloatEquals, false, "", 1e20, 1e20+1e10, 1e-9)
+	testCheck(c, check.FloatEquals, true, "", 1e20, 1e20+1e10, 1e11)
+
+	testCheck(c, check.FloatEquals, true, "", math.NaN(), math.NaN(), 0.1)
+	testCheck(c, check.FloatEquals, false, "", math.NaN(), 1.0, 0.1)
+	testCheck(c, check.FloatEquals, false, "", 1.0, math.NaN(), math.Inf(1))
+	testCheck(c, check.FloatEquals, true, "", math.Inf(1), math.Inf(1), 0.1)
+	testCheck(c, check.FloatEquals, false, "", math.Inf(1), math.Inf(-1), 0.1)
+	testCheck(c, check.FloatEquals, false, "", math.Inf(1), 1e300, 0.1)
+
+	testCheck(c, check.FloatEquals, false, "epsilon must not be negative", 1.0, 1.0, -0.1)
+	testCheck(c, check.FloatEquals, false, "epsilon must not be negative", 1.0, 1.0, math.NaN())
+	testCheck(c, check.FloatEquals, false, "expected has type string, which isn't a number", 1.0, "1", 0.1)
+}
+
+func (s *CheckersS) TestFloatRelEquals(c *check.C) {
+	testInfo(c, check.FloatRelEquals, "FloatRelEquals", []string{"obtained", "expected", "tolerance"})
+
+	testCheck(c, check.FloatRelEquals, true, "", 1.0, 1.0, 0.0)
+	testCheck(c, check.FloatRelEquals, true, "", 1e20, 1e20+1e10, 1e-9)
+	testCheck(c, check.FloatRelEquals, false, "", 1e20, 1.1e20, 1e-9)
+	testCheck(c, check.FloatRelEquals, true, "", 100.0, 104.0, 0.05)
+	testCheck(c, check.FloatRelEquals, false, "", 100.0, 106.0, 0.05)
+	testCheck(c, check.FloatRelEquals, false, "", 0.0, 1e-12, 0.5)
+
+	testCheck(c, check.FloatRelEquals, true, "", math.NaN(), math.NaN(), 0.1)
+	testCheck(c, check.FloatRelEquals, false, "", math.Inf(1), 1e300, 0.5)
+	testCheck(c, check.FloatRelEquals, false, "tolerance must not be negative", 1.0, 1.0, -0.1)
+}
+
+func (s *CheckersS) TestTimeEquals(c *check.C) {
+	testInfo(c, check.TimeEquals, "TimeEquals", []string{"obtained", "expected"})
+
+	now := time.Now()
+	testCheck(c, check.TimeEquals, true, "", now, now.Round(0))
+	testCheck(c, check.TimeEquals, true, "", now, now.In(time.FixedZone("X", 3600)))
+	testCheck(c, check.TimeEquals, false, "", now, now.Add(1))
+	testCheck(c, check.TimeEquals, false, "obtained has type string, which isn't a time.Time", "now", now)
+	testCheck(c, check.TimeEquals, false, "expected has type *time.Time, which isn't a time.Time", now, &now)
+}
+
+func (s *CheckersS) TestWithinDuration(c *check.C) {
+	testInfo(c, check.WithinDuration, "WithinDuration", []string{"obtained", "expected", "delta"})
+
+	now := time.Now()
+	testCheck(c, check.WithinDuration, true, "", now, now.UTC(), time.Duration(0))
+	testCheck(c, check.WithinDuration, true, "", now.Add(time.Second), now, time.Second)
+	testCheck(c, check.WithinDuration, true, "", now.Add(-time.Second), now, time.Second)
+	testCheck(c, check.WithinDuration, false, "", now.Add(time.Second+1), now, time.Second)
+	testCheck(c, check.WithinDuration, false, "", now.Add(-time.Second-1), now, time.Second)
+	testCheck(c, check.WithinDuration, false, "delta must be a time.Duration", now, now, 1)
+	testCheck(c, check.WithinDuration, false, "expected has type int, which isn't a time.Time", now, 0, time.Second)
+}
+
+func (s *CheckersS) TestEach(c *check.C) {
+	checker := check.Each(check.GreaterThan)
+	testInfo(c, checker, "Each(GreaterThan)", []string{"container", "bound"})
+
+	testCheck(c, checker, true, "", []int{2, 3, 4}, 1)
+	testCheck(c, checker, true, "", [2]int{2, 3}, 1)
+	testCheck(c, checker, true, "", []int{}, 1)
+	testCheck(c, checker, false, "GreaterThan didn't match [0], [2]", []int{1, 3, 0}, 1)
+	testCheck(c, checker, false, "GreaterThan didn't match [\"b\"]", map[string]int{"a": 2, "b": 1}, 1)
+	testCheck(c, checker, false, "[1]: obtained has type string, which is neither a number nor a time.Time",
+		[]interface{}{2, "3"}, 1)
+	testCheck(c, checker, false, "int is not a supported container", 1, 1)
+	testCheck(c, check.Not(checker), true, "", []int{1, 3, 0}, 1)
+
+	testCheck(c, check.Each(check.HasLen), true, "", [][]int{{1}, {2}}, 1)
+	testCheck(c, check.Each(check.Not(check.IsNil)), false, "Not(IsNil) didn't match [1]", []error{errMissing, nil})
+
+	// The expected value must be of the type of the items, as with Contains.
+	equals := check.Each(check.Equals)
+	testCheck(c, equals, true, "", []int{1, 1}, 1)
+	testCheck(c, equals, false, "container has items of type int but expected element is a string", []int{1, 1}, "1")
+	testCheck(c, equals, true, "", []error{nil, nil}, nil)
+	testCheck(c, check.Each(check.DeepEquals), true, "", []interface{}{1, 1}, 1)
+	testCheck(c, check.Each(check.DeepEquals), false,
+		"container has items of interface type error but expected element does not implement it",
+		[]error{errMissing}, "missing")
+
+	// Also when wrapped in other checkers.
+	testCheck(c, check.Each(check.Not(check.Equals)), false,
+		"container has items of type int but expected element is a string", []int{1, 2}, "3")
+	testCheck(c, check.Each(check.Not(check.Equals)), true, "", []int{1, 2}, 3)
+	testCheck(c, check.Any(check.Or(check.Equals, check.Equals)), false,
+		"container has items of type string but expected element is a int", []string{"a"}, "a", 1)
+	testCheck(c, check.Any(check.Or(check.Equals, check.Equals)), true, "", []string{"a"}, "b", "a")
+	testCheck(c, check.Each(check.AllOf(check.GreaterThan, check.Not(check.Equals))), false,
+		"container has items of type int but expected element is a string", []int{1, 2}, 0, "3")
+	testCheck(c, check.Each(check.AllOf(check.GreaterThan, check.Not(check.Equals))), true, "", []int{1, 2}, 0.5, 3)
+}
+
+func (s *CheckersS) TestAny(c *check.C) {
+	checker := check.Any(check.Equals)
+	testInfo(c, checker, "Any(Equals)", []string{"container", "expected"})
+
+	testCheck(c, checker, true, "", []string{"a", "b"}, "b")
+	testCheck(c, checker, true, "", map[int]string{1: "a", 2: "b"}, "b")
+	testCheck(c, checker, false, "Equals didn't match any item", []string{"a", "b"}, "c")
+	testCheck(c, checker, false, "Container is empty", []string{}, "c")
+	testCheck(c, check.Not(checker), true, "", []string{"a", "b"}, "c")
+	testCheck(c, checker, false, "container has items of type string but expected element is a int", []string{"a", "1"}, 1)
+
+	matches := check.Any(check.ErrorMatches)
+	testCheck(c, matches, true, "", []interface{}{1, errors.New("some error")}, "some.*")
+	testCheck(c, matches, false, "ErrorMatches didn't match any item; [0]: Value is not an error",
+		[]interface{}{1, errors.New("other error")}, "some.*")
+}
+
+func (s *CheckersS) TestSameElements(c *check.C) {
+	testInfo(c, check.SameElements, "SameElements", []string{"obtained", "expected"})
+
+	testCheck(c, check.SameElements, true, "", []int{1, 2, 3}, []int{3, 1, 2})
+	testCheck(c, check.SameElements, true, "", []int{1, 1, 2}, [3]int{1, 2, 1})
+	testCheck(c, check.SameElements, true, "", []int{}, []int(nil))
+	testCheck(c, check.SameElements, true, "", [][]int{{1}, {2}}, [][]int{{2}, {1}})
+	testCheck(c, check.SameElements, false, "Missing: 4", []int{1, 2}, []int{2, 4, 1})
+	testCheck(c, check.SameElements, false, "Extra: 1", []int{1, 1, 2}, []int{1, 2})
+	testCheck(c, check.SameElements, false, "Missing: \"c\"; Extra: \"a\", \"d\"",
+		[]string{"a", "b", "d"}, []string{"b", "c"})
+	testCheck(c, check.SameElements, false, "expected is a string, not a slice or array", []int{}, "a")
+	testCheck(c, check.Not(check.SameElements), true, "", []int{1, 2}, []int{2, 4, 1})
+}
+
+func (s *CheckersS) TestHasKey(c *check.C) {
+	testInfo(c, check.HasKey, "HasKey", []string{"map", "key"})
+
+	m := map[string]int{"a": 1, "b": 0}
+	testCheck(c, check.HasKey, true, "", m, "a")
+	testCheck(c, check.HasKey, true, "", m, "b")
+	testCheck(c, check.HasKey, false, "", m, "c")
+	testCheck(c, check.HasKey, false, "", map[string]int(nil), "a")
+	testCheck(c, check.HasKey, true, "", map[interface{}]bool{nil: true}, nil)
+	testCheck(c, check.HasKey, false, "key is a int, but the map has keys of type string", m, 1)
+	testCheck(c, check.HasKey, false, "key is nil, but the map has keys of type string", m, nil)
+	testCheck(c, check.HasKey, false, "map is a []string, not a map", []string{"a"}, "a")
+	testCheck(c, check.Not(check.HasKey), true, "", m, "c")
+}
+
+func (s *CheckersS) TestHasKeys(c *check.C) {
+	testInfo(c, check.HasKeys, "HasKeys", []string{"map", "keys"})
+
+	m := map[string]int{"a": 1, "b": 0, "c": 2}
+	testCheck(c, check.HasKeys, true, "", m, []string{"a", "c"})
+	testCheck(c, check.HasKeys, true, "", m, []string{})
+	testCheck(c, check.HasKeys, false, "Missing keys: \"d\", \"e\"", m, [3]string{"d", "a", "e"})
+	testCheck(c, check.HasKeys, false, "keys[1] is a int, but the map has keys of type string", m, []interface{}{"a", 1})
+	testCheck(c, check.HasKeys, false, "keys is a string, not a slice or array", m, "a")
+	testCheck(c, check.Not(check.HasKeys), true, "", m, []string{"d"})
+}
+
+type fieldsMeta struct {
+	Owner  string
+	Labels []string
+}
+
+type fieldsObject struct {
+	Name    string
+	Size    int
+	Meta    fieldsMeta
+	Parent  *fieldsObject
+	private int
+}
+
+func (s *CheckersS) TestFields(c *check.C) {
+	testInfo(c, check.Fields, "Fields", []string{"obtained", "fields"})
+
+	obj := &fieldsObject{
+		Name:   "child",
+		Size:   3,
+		Meta:   fieldsMeta{"alice", []string{"x"}},
+		Parent: &fieldsObject{Name: "root"},
+	}
+	testCheck(c, check.Fields, true, "", obj, map[string]interface{}{
+		"Name":        "child",
+		"Meta.Owner":  "alice",
+		"Meta.Labels": []string{"x"},
+		"Parent.Name": "root",
+	})
+	testCheck(c, check.Fields, true, "", *obj, map[string]interface{}{
+		"Size":          check.Checked(check.Between, 1, 5),
+		"Parent":        check.NotNil,
+		"Parent.Parent": check.IsNil,
+	})
+	testCheck(c, check.Fields, false, "Meta.Owner is \"alice\", not \"bob\"; Size is 3, not 4", obj,
+		map[string]interface{}{"Name": "child", "Meta.Owner": "bob", "Size": 4})
+	testCheck(c, check.Fields, false, "Size is 3, GreaterThan didn't match", obj,
+		map[string]interface{}{"Size": check.Checked(check.GreaterThan, 5)})
+	testCheck(c, check.Fields, false, "Meta.Labels is []string{\"x\"}, Each(Equals) didn't match: Equals didn't match [0]", obj,
+		map[string]interface{}{"Meta.Labels": check.Checked(check.Each(check.Equals), "y")})
+	testCheck(c, check.Not(check.Fields), true, "", obj, map[string]interface{}{"Size": 4})
+
+	testCheck(c, check.Fields, false, "obtained.Parent.Parent is nil", obj,
+		map[string]interface{}{"Parent.Parent.Name": ""})
+	testCheck(c, check.Fields, false, "obtained has no field Missing", obj,
+		map[string]interface{}{"Missing": ""})
+	testCheck(c, check.Fields, false, "obtained.private is unexported", obj,
+		map[string]interface{}{"private": 0})
+	testCheck(c, check.Fields, false, "obtained.Name is not a struct", obj,
+		map[string]interface{}{"Name.Len": 0})
+	testCheck(c, check.Fields, false, "Size: bound has type string, which is neither a number nor a time.Time", obj,
+		map[string]interface{}{"Size": check.Checked(check.GreaterThan, "a")})
+	testCheck(c, check.Fields, false, "Wrong number of arguments for GreaterThan on Size: want 1, got 0", obj,
+		map[string]interface{}{"Size": check.GreaterThan})
+	testCheck(c, check.Fields, false, "fields must be a map[string]interface{}", obj, map[string]int{"Size": 3})
+}
+
+func (s *CheckersS) TestJSONEquals(c *check.C) {
+	testInfo(c, check.JSONEquals, "JSONEquals", []string{"obtained", "expected"})
+
+	testCheck(c, check.JSONEquals, true, "", `{"a": 1, "b": [true, null]}`, `{"b":[true,null],"a":1}`)
+	testCheck(c, check.JSONEquals, true, "", []byte(`{"n": 1.0}`), `{"n": 1e0}`)
+	testCheck(c, check.JSONEquals, true, "", `{"name": "x", "tags": ["a"]}`,
+		struct {
+			Tags []string `json:"tags"`
+			Name string   `json:"name"`
+		}{[]string{"a"}, "x"})
+	testCheck(c, check.JSONEquals, true, "", json.RawMessage(`"s"`), "\n\"s\"\n")
+	testCheck(c, check.JSONEquals, true, "", `12345678901234567890`, `12345678901234567890`)
+	testCheck(c, check.JSONEquals, false, "$: obtained 12345678901234567891, expected 12345678901234567890",
+		`12345678901234567891`, `12345678901234567890`)
+
+	testCheck(c, check.JSONEquals, false, "$.a: obtained 1, expected 2", `{"a": 1}`, `{"a": 2}`)
+	testCheck(c, check.JSONEquals, false, ""+
+		"$.meta[\"created at\"]: unexpected \"today\"; "+
+		"$.meta.owner: obtained \"bob\", expected \"alice\"; "+
+		"$.tags[1]: missing, expected \"b\"; "+
+		"$.x: missing, expected {\"y\":[1]}",
+		`{"meta": {"owner": "bob", "created at": "today"}, "tags": ["a"]}`,
+		`{"meta": {"owner": "alice"}, "tags": ["a", "b"], "x": {"y": [1]}}`)
+	testCheck(c, check.JSONEquals, false, "$[0]: obtained \"1\", expected 1; $[1]: unexpected null",
+		`["1", null]`, `[1]`)
+	testCheck(c, check.JSONEquals, false, "$: obtained {}, expected []", `{}`, `[]`)
+	testCheck(c, check.Not(check.JSONEquals), true, "", `{"a": 1}`, `{"a": 2}`)
+
+	testCheck(c, check.JSONEquals, false, "obtained isn't valid JSON: unexpected EOF", `{"a": `, `{}`)
+	testCheck(c, check.JSONEquals, false, "expected isn't valid JSON: unexpected data after the top-level value",
+		`{}`, `{} {}`)
+	testCheck(c, check.JSONEquals, false,
+		"expected can't be marshalled as JSON: json: unsupported type: chan int", `{}`, make(chan int))
+}
+
+func (s *CheckersS) TestXMLEquals(c *check.C) {
+	testInfo(c, check.XMLEquals, "XMLEquals", []string{"obtained", "expected"})
+
+	testCheck(c, check.XMLEquals, true, "",
+		`<?xml version="1.0"?><feed a="1" b="2"><!-- c --><entry> x </entry></feed>`,
+		"<feed b='2' a='1'>\n  <entry>x</entry>\n</feed>")
+	testCheck(c, check.XMLEquals, true, "",
+		`<a:feed xmlns:a="urn:x"><a:entry/></a:feed>`,
+		[]byte(`<feed xmlns="urn:x"><entry></entry></feed>`))
+	testCheck(c, check.XMLEquals, true, "", `<item><Name>x</Name></item>`,
+		struct {
+			XMLName xml.Name `xml:"item"`
+			Name    string
+		}{Name: "x"})
+
+	testCheck(c, check.XMLEquals, false, "/: obtained element <a>, expected <b>", `<a/>`, `<b/>`)
+	testCheck(c, check.XMLEquals, false, ""+
+		"/feed/@lang: missing, expected \"en\"; "+
+		"/feed/entry[2]/@id: obtained \"3\", expected \"2\"; "+
+		"/feed/entry[2]: obtained text \"b\", expected \"c\"; "+
+		"/feed/link[1]: unexpected",
+		`<feed><entry id="1">a</entry><entry id="3">b</entry><link/></feed>`,
+		`<feed lang="en"><entry id="1">a</entry><entry id="2">c</entry></feed>`)
+	testCheck(c, check.XMLEquals, false, "/feed/entry[1]: obtained element <entry>, expected <link>; /feed/entry[1]: missing",
+		`<feed><entry/></feed>`, `<feed><link/><entry/></feed>`)
+	testCheck(c, check.XMLEquals, false, "/: obtained element <{urn:x}feed>, expected <feed>",
+		`<feed xmlns="urn:x"/>`, `<feed/>`)
+	testCheck(c, check.Not(check.XMLEquals), true, "", `<a/>`, `<b/>`)
+
+	testCheck(c, check.XMLEquals, false, "obtained isn't valid XML: XML syntax error on line 1: unexpected EOF",
+		`<a>`, `<a/>`)
+	testCheck(c, check.XMLEquals, false, "obtained isn't valid XML: more than one root element", `<a/><a/>`, `<a/>`)
+	testCheck(c, check.XMLEquals, false, "expected isn't valid XML: no root element", `<a/>`, ``)
+	testCheck(c, check.XMLEquals, false, "expected isn't valid XML: text outside of the root element", `<a/>`, `x<a/>`)
+}
@@ -0,0 +1,312 @@
+package check
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Maximum number of differences logged for a single check.
+const maxDiffs = 20
+
+// -----------------------------------------------------------------------
+// Structural differences between two values.
+
+type valueDiffer struct {
+	diffs   []string
+	omitted int
+	atRoot  bool
+	visited map[[2]uintptr]bool
+}
+
+// diffValues returns the differences found between the obtained and
+// expected values, one per line and annotated with the path to the
+// differing value (e.g. `.Items[3].Name: "a" != "b"`). Nothing is
+// returned when the values differ as a whole, since there's nothing
+// to add to them being logged.
+func diffValues(obtained, expected interface{}) []string {
+	d := &valueDiffer{visited: make(map[[2]uintptr]bool)}
+	d.diff("", reflect.ValueOf(obtained), reflect.ValueOf(expected))
+	if d.atRoot {
+		return nil
+	}
+	if d.omitted > 0 {
+		d.diffs = append(d.diffs, fmt.Sprintf("... and %d more differences", d.omitted))
+	}
+	return d.diffs
+}
+
+func (d *valueDiffer) report(path, format string, args ...interface{}) {
+	if len(d.diffs) == maxDiffs {
+		d.omitted++
+		return
+	}
+	if path == "" {
+		d.atRoot = true
+	}
+	d.diffs = append(d.diffs, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (d *valueDiffer) diff(path string, a, b reflect.Value) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.report(path, "%s != %s", formatValue(a), formatValue(b))
+		}
+		return
+	}
+	if a.Type() != b.Type() {
+		d.report(path, "type %s != type %s", a.Type(), b.Type())
+		return
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.report(path, "%s != %s", formatValue(a), formatValue(b))
+			}
+			return
+		}
+		if a.Kind() == reflect.Ptr {
+			if a.Pointer() == b.Pointer() {
+				return
+			}
+			key := [2]uintptr{a.Pointer(), b.Pointer()}
+			if d.visited[key] {
+				return
+			}
+			d.visited[key] = true
+		}
+		d.diff(path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i, n := 0, a.NumField(); i < n; i++ {
+			d.diff(path+"."+a.Type().Field(i).Name, a.Field(i), b.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() {
+			d.report(path, "%s != %s", formatValue(a), formatValue(b))
+			return
+		}
+		n := a.Len()
+		if b.Len() > n {
+			n = b.Len()
+		}
+		for i := 0; i < n; i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= a.Len():
+				d.report(elemPath, "<missing> != %s", formatValue(b.Index(i)))
+			case i >= b.Len():
+				d.report(elemPath, "%s != <missing>", formatValue(a.Index(i)))
+			default:
+				d.diff(elemPath, a.Index(i), b.Index(i))
+			}
+		}
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() {
+			d.report(path, "%s != %s", formatValue(a), formatValue(b))
+			return
+		}
+		keys := make(map[string]reflect.Value)
+		var names []string
+		for _, v := range [2]reflect.Value{a, b} {
+			for _, key := range v.MapKeys() {
+				name := formatValue(key)
+				if _, ok := keys[name]; !ok {
+					keys[name] = key
+					names = append(names, name)
+				}
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			elemPath := path + "[" + name + "]"
+			av := a.MapIndex(keys[name])
+			bv := b.MapIndex(keys[name])
+			switch {
+			case !av.IsValid():
+				d.report(elemPath, "<missing> != %s", formatValue(bv))
+			case !bv.IsValid():
+				d.report(elemPath, "%s != <missing>", formatValue(av))
+			default:
+				d.diff(elemPath, av, bv)
+			}
+		}
+	case reflect.Func:
+		if !a.IsNil() || !b.IsNil() {
+			d.report(path, "%s != %s (functions are only equal when nil)",
+				formatValue(a), formatValue(b))
+		}
+	default:
+		as, bs := formatValue(a), formatValue(b)
+		if as != bs {
+			d.report(path, "%s != %s", as, bs)
+		} else if a.Kind() == reflect.Float32 || a.Kind() == reflect.Float64 {
+			// NaN is never equal to itself.
+			if f := a.Float(); f != f {
+				d.report(path, "%s != %s", as, bs)
+			}
+		}
+	}
+}
+
+// formatValue formats v like %#v would, but also works with values taken
+// from unexported struct fields.
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	if v.CanInterface() {
+		return fmt.Sprintf("%#v", v.Interface())
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "0x" + strconv.FormatUint(v.Uint(), 16)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Complex())
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.Interface:
+		if v.IsNil() {
+			return fmt.Sprintf("(%s)(nil)", v.Type())
+		}
+		if v.Kind() == reflect.Interface {
+			return formatValue(v.Elem())
+		}
+	}
+	return fmt.Sprintf("%s{...}", v.Type())
+}
+
+// -----------------------------------------------------------------------
+// Unified line differences between two multi-line strings.
+
+// Number of unchanged lines shown around each change.
+const diffContext = 3
+
+// Beyond this, computing the line differences gets too expensive.
+const maxDiffCells = 1 << 20
+
+// diffLines returns the unified differences between the lines of a and
+// b, with each line quoted, or nil if the strings are too large.
+func diffLines(a, b string) []string {
+	al := splitLines(a)
+	bl := splitLines(b)
+	n, m := len(al), len(bl)
+	if n*m > maxDiffCells {
+		return nil
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence
+	// of al[i:] and bl[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	type edit struct {
+		op   byte
+		a, b int // Line numbers in a and b, from zero.
+		line string
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && al[i] == bl[j]:
+			edits = append(edits, edit{' ', i, j, al[i]})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', i, j, al[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', i, j, bl[j]})
+			j++
+		}
+	}
+
+	var lines []string
+	for k := 0; k < len(edits); {
+		if edits[k].op == ' ' {
+			k++
+			continue
+		}
+		// Extend the hunk while changes are close enough together.
+		start := k - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := k
+		for end < len(edits) {
+			if edits[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(edits) && edits[next].op == ' ' {
+				next++
+			}
+			if next == len(edits) || next-end > 2*diffContext {
+				end += diffContext
+				if end > next {
+					end = next
+				}
+				break
+			}
+			end = next
+		}
+		var acount, bcount int
+		for _, e := range edits[start:end] {
+			if e.op != '+' {
+				acount++
+			}
+			if e.op != '-' {
+				bcount++
+			}
+		}
+		lines = append(lines, fmt.Sprintf("@@ -%s +%s @@",
+			hunkRange(edits[start].a, acount), hunkRange(edits[start].b, bcount)))
+		for _, e := range edits[start:end] {
+			lines = append(lines, string(e.op)+strconv.Quote(e.line))
+		}
+		k = end
+	}
+	return lines
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return strconv.Itoa(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits s into lines, keeping the line breaks.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
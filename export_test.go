@@ -1,6 +1,9 @@
 package check
 
-import "io"
+import (
+	"io"
+	"testing"
+)
 
 type TestReporter interface {
 	testReporter
@@ -31,3 +34,42 @@ func NewOutputWriter(writer io.Writer, verbosity uint8) *outputWriter {
 func (c *C) FakeSkip(reason string) {
 	c.reason = reason
 }
+
+// RunAllOf runs the given suites with RunAll, as if they were the only
+// ones registered.
+func RunAllOf(suites []interface{}, runConf *RunConf) *Result {
+	registered := allSuites
+	allSuites = suites
+	defer func() { allSuites = registered }()
+	return RunAll(runConf)
+}
+
+// TestingSubtestsOf runs the given suites with TestingSubtests, as if they
+// were the only ones registered.
+func TestingSubtestsOf(t *testing.T, suites []interface{}) {
+	registered := allSuites
+	allSuites = suites
+	defer func() { allSuites = registered }()
+	TestingSubtests(t)
+}
+
+// ListAllOf lists the given suites with ListAll, as if they were the only
+// ones registered.
+func ListAllOf(suites []interface{}, runConf *RunConf) []string {
+	registered := allSuites
+	allSuites = suites
+	defer func() { allSuites = registered }()
+	return ListAll(runConf)
+}
+
+func ReadFailedTests(path string) ([]string, error) {
+	return readFailedTests(path)
+}
+
+func WriteFailedTests(path string, result *Result) error {
+	return writeFailedTests(path, result)
+}
+
+func FailedFilter(names []string) string {
+	return failedFilter(names)
+}
@@ -480,3 +480,114 @@ func (s *FixtureS) TestSkipTest(c *C) {
 	c.Assert(len(helper.calls), Equals, 6)
 	c.Assert(result.Skipped, Equals, 1)
 }
+
+// -----------------------------------------------------------------------
+// Cleanup() registration within fixture and test methods.
+
+type CleanupHelper struct {
+	calls   []string
+	panicOn string
+}
+
+func (s *CleanupHelper) cleanup(c *C, name string) {
+	c.Cleanup(func() {
+		s.calls = append(s.calls, name)
+		if name == s.panicOn {
+			panic(name)
+		}
+	})
+}
+
+func (s *CleanupHelper) SetUpSuite(c *C) {
+	s.calls = append(s.calls, "SetUpSuite")
+	s.cleanup(c, "SetUpSuite cleanup 1")
+	s.cleanup(c, "SetUpSuite cleanup 2")
+}
+
+func (s *CleanupHelper) TearDownSuite(c *C) {
+	s.calls = append(s.calls, "TearDownSuite")
+}
+
+func (s *CleanupHelper) SetUpTest(c *C) {
+	s.calls = append(s.calls, "SetUpTest")
+	s.cleanup(c, "SetUpTest cleanup")
+}
+
+func (s *CleanupHelper) TearDownTest(c *C) {
+	s.calls = append(s.calls, "TearDownTest")
+}
+
+func (s *CleanupHelper) Test1(c *C) {
+	s.calls = append(s.calls, "Test1")
+	s.cleanup(c, "Test1 cleanup")
+}
+
+func (s *CleanupHelper) Test2(c *C) {
+	s.calls = append(s.calls, "Test2")
+	s.cleanup(c, "Test2 cleanup")
+	c.FailNow()
+}
+
+func (s *FixtureS) TestCleanupOrder(c *C) {
+	helper := CleanupHelper{}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output})
+	c.Check(result.Succeeded, Equals, 1)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(helper.calls, DeepEquals, []string{
+		"SetUpSuite",
+		"SetUpTest",
+		"Test1",
+		"TearDownTest",
+		"Test1 cleanup",
+		"SetUpTest cleanup",
+		"SetUpTest",
+		"Test2",
+		"TearDownTest",
+		"Test2 cleanup",
+		"SetUpTest cleanup",
+		"TearDownSuite",
+		"SetUpSuite cleanup 2",
+		"SetUpSuite cleanup 1",
+	})
+}
+
+func (s *FixtureS) TestCleanupPanic(c *C) {
+	helper := CleanupHelper{panicOn: "Test1 cleanup"}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output})
+	c.Check(result.Succeeded, Equals, 0)
+	c.Check(result.FixturePanicked, Equals, 1)
+	c.Check(result.Missed, Equals, 2)
+	c.Check(helper.calls, DeepEquals, []string{
+		"SetUpSuite",
+		"SetUpTest",
+		"Test1",
+		"TearDownTest",
+		"Test1 cleanup",
+		"SetUpTest cleanup",
+		"TearDownSuite",
+		"SetUpSuite cleanup 2",
+		"SetUpSuite cleanup 1",
+	})
+	c.Check(output.value, Matches, "\n-+\n"+
+		"PANIC: fixture_test\\.go:[0-9]+: CleanupHelper\\.Test1\n\n"+
+		"\\.\\.\\. Panic: Test1 cleanup \\(PC=[xA-F0-9]+\\)\n\n"+
+		"(.|\n)*"+
+		"PANIC: fixture_test\\.go:[0-9]+: CleanupHelper\\.Test1\n\n"+
+		"\\.\\.\\. Panic: Fixture has panicked \\(see related PANIC\\)\n$")
+}
+
+func (s *FixtureS) TestCleanupPanicOnSuite(c *C) {
+	helper := CleanupHelper{panicOn: "SetUpSuite cleanup 2"}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output})
+	c.Check(result.FixturePanicked, Equals, 1)
+	c.Check(helper.calls[len(helper.calls)-2:], DeepEquals, []string{
+		"SetUpSuite cleanup 2",
+		"SetUpSuite cleanup 1",
+	})
+	c.Check(output.value, Matches, "(?s).*\n-+\n"+
+		"PANIC: fixture_test\\.go:[0-9]+: CleanupHelper\\.SetUpSuite\n\n"+
+		"\\.\\.\\. Panic: SetUpSuite cleanup 2 .*")
+}
@@ -71,11 +71,92 @@ func (c *C) Skip(reason string) {
 	if reason == "" {
 		panic("Missing reason why the test is being skipped")
 	}
+	if c.polledBy != "" {
+		panic("Skip called from " + c.polledBy)
+	}
 	c.reason = reason
 	c.setStatus(skippedSt)
 	c.stopNow()
 }
 
+// Parallel signals that the running test may be run in parallel with
+// other parallel tests of the same suite. The test is paused until all
+// the serial tests in the suite have run, and then resumed once fewer
+// than RunConf.Parallelism parallel tests are running. SetUpTest and
+// TearDownTest still run for each test, and SetUpSuite and TearDownSuite
+// still run before and after all of them. In fail-fast mode, the tests
+// still waiting once a test has failed are stopped and reported as missed.
+// Parallel tests are never retried when failing, whatever RunConf.Retry.
+func (c *C) Parallel() {
+	if c.kind != testKd {
+		panic("Parallel called from a fixture method")
+	}
+	if c.polledBy != "" {
+		panic("Parallel called from " + c.polledBy)
+	}
+	if c.parallel {
+		panic("Parallel called more than once")
+	}
+	if c.subtestPath() != "" {
+		panic("Parallel called from a subtest")
+	}
+	c.parallel = true
+	c.StopTimer()
+	// Time spent waiting doesn't count towards the test timeout.
+	waiting := c.timeout != nil && c.timeout.Stop()
+	c.gate.wg.Add(1)
+	c.paused <- true
+	<-c.gate.release
+	if !c.gate.enter() {
+		// An earlier test failed in fail-fast mode.
+		c.reason = notRunReason
+		c.setStatus(missedSt)
+		c.stopNow()
+	}
+	c.entered = true
+	if waiting {
+		c.timeout.Reset(c.budget)
+	}
+	c.StartTimer()
+}
+
+// Run runs f as a subtest of the running test, with the given name, and
+// waits for it to finish. The subtest has its own log and status, and is
+// reported and counted on its own as "SuiteName.TestName/name". The running
+// test fails if the subtest fails or panics. Subtests may be selected by
+// the filter as "SuiteName.TestName/name", where each part is a separate
+// regular expression. Run returns false if the subtest failed.
+func (c *C) Run(name string, f func(c *C)) bool {
+	if c.kind != testKd {
+		panic("Run called from a fixture method")
+	}
+	if c.polledBy != "" {
+		panic("Run called from " + c.polledBy)
+	}
+	sub := c.runner.runSubtest(c, name, f)
+	if sub == nil {
+		return true
+	}
+	switch sub.status() {
+	case failedSt, panickedSt, fixturePanickedSt:
+		c.Fail()
+		return false
+	}
+	return true
+}
+
+// Cleanup registers f to be called once the running call is done. Functions
+// registered from SetUpSuite are called after TearDownSuite, and the ones
+// registered from SetUpTest or from a test are called after TearDownTest.
+// They're called in the reverse order they were registered in, and a panic
+// in any of them is reported like a panic in a fixture method.
+func (c *C) Cleanup(f func()) {
+	if c.kind == fixtureKd && (c.method == c.runner.tearDownTest || c.method == c.runner.tearDownSuite) {
+		panic("Cleanup called from a teardown method")
+	}
+	c.cleanups.push(c.method, f)
+}
+
 // -----------------------------------------------------------------------
 // Basic logging.
 
@@ -217,6 +298,9 @@ func (c *C) internalCheck(funcName string, obtained interface{}, checker Checker
 		for i := 0; i != len(params); i++ {
 			c.logValue(names[i], params[i])
 		}
+		if _, ok := checker.(diffChecker); ok && len(params) == 2 {
+			c.logDiff(names[0], names[1], params[0], params[1])
+		}
 		if comment != nil {
 			c.logString(comment.CheckCommentString())
 		}
@@ -229,3 +313,90 @@ func (c *C) internalCheck(funcName string, obtained interface{}, checker Checker
 	}
 	return true
 }
+
+// -----------------------------------------------------------------------
+// Checks polled over time.
+
+// Eventually runs f repeatedly, waiting interval between runs, until it
+// runs without failing or timeout has passed. The checks in f are made
+// against a scratch *C, so failures in earlier runs aren't logged. If f
+// is still failing once timeout has passed, the failures of its last run
+// are logged and the test is marked as failed. Eventually returns whether
+// f ended up passing. Since only checks are meant to be made in f, calling
+// Run, Parallel or Skip from it panics. For example:
+//
+//     c.Eventually(func(c *C) {
+//         c.Assert(server.Ready(), Equals, true)
+//     }, 5*time.Second, 100*time.Millisecond)
+//
+func (c *C) Eventually(f func(c *C), timeout, interval time.Duration) bool {
+	return c.poll("Eventually", f, timeout, interval)
+}
+
+// Consistently runs f repeatedly, waiting interval between runs, until
+// timeout has passed, making sure that it never fails. The checks in f are
+// made against a scratch *C, and if any run fails, its failures are logged,
+// the test is marked as failed, and no more runs are made. Consistently
+// returns whether f kept passing. As with Eventually, calling Run,
+// Parallel or Skip from f panics.
+func (c *C) Consistently(f func(c *C), timeout, interval time.Duration) bool {
+	return c.poll("Consistently", f, timeout, interval)
+}
+
+func (c *C) poll(funcName string, f func(c *C), timeout, interval time.Duration) bool {
+	eventually := funcName == "Eventually"
+	deadline := time.Now().Add(timeout)
+	for attempts := 1; ; attempts++ {
+		scratch := c.pollOnce(funcName, f)
+		passed := scratch.status() == succeededSt
+		if passed && eventually {
+			return true
+		}
+		if !passed && (!eventually || !time.Now().Before(deadline)) {
+			c.logCaller(2)
+			if eventually {
+				c.logf("... %s: still failing after %s, %d attempts:", funcName, timeout, attempts)
+			} else {
+				c.logf("... %s: failed within %s, on attempt %d:", funcName, timeout, attempts)
+			}
+			c.writeLog([]byte(scratch.logb.String()))
+			c.Fail()
+			return false
+		}
+		if !time.Now().Before(deadline) {
+			return true
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Run f once against a scratch copy of c, with a log of its own. Since
+// checks may stop the goroutine running them, f runs in a goroutine of
+// its own, and a panic in f is raised again in the calling goroutine.
+func (c *C) pollOnce(funcName string, f func(c *C)) *C {
+	scratch := &C{
+		method:    c.method,
+		kind:      c.kind,
+		testName:  c.testName,
+		logb:      &logger{},
+		tempDir:   c.tempDir,
+		startTime: c.startTime,
+		runner:    c.runner,
+		cleanups:  c.cleanups,
+		polledBy:  funcName,
+	}
+	var panicked interface{}
+	done := make(chan bool)
+	go func() {
+		defer func() {
+			panicked = recover()
+			close(done)
+		}()
+		f(scratch)
+	}()
+	<-done
+	if panicked != nil {
+		panic(panicked)
+	}
+	return scratch
+}
@@ -7,7 +7,9 @@ import (
 	"os"
 	"reflect"
 	"runtime"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/elopio/check"
 )
@@ -479,6 +481,97 @@ func (s *HelpersS) TestTestName(c *check.C) {
 	c.Check(helper.name5, check.Equals, "")
 }
 
+// -----------------------------------------------------------------------
+// Checks polled over time.
+
+func (s *HelpersS) TestEventuallySucceed(c *check.C) {
+	attempts := 0
+	testHelperSuccess(c, "Eventually(...)", true, func() interface{} {
+		return c.Eventually(func(c *check.C) {
+			attempts++
+			c.Assert(attempts, check.Equals, 3)
+		}, time.Second, time.Millisecond)
+	})
+	c.Check(attempts, check.Equals, 3)
+}
+
+func (s *HelpersS) TestEventuallyFail(c *check.C) {
+	attempts := 0
+	log := "(?s)helpers_test\\.go:[0-9]+:.*\nhelpers_test\\.go:[0-9]+:\n" +
+		"    return c\\.Eventually\\(func\\(c \\*check\\.C\\) \\{\n.*" +
+		"\\.+ Eventually: still failing after 20ms, [0-9]+ attempts:\n" +
+		"helpers_test\\.go:[0-9]+:\n" +
+		"    c\\.Assert\\(attempts, check\\.Equals, -1\\)\n" +
+		"\\.+ obtained int = [0-9]+\n" +
+		"\\.+ expected int = -1\n\n"
+	testHelperFailure(c, "Eventually(...)", false, false, log, func() interface{} {
+		return c.Eventually(func(c *check.C) {
+			attempts++
+			c.Assert(attempts, check.Equals, -1)
+		}, 20*time.Millisecond, time.Millisecond)
+	})
+	// Only the last attempt is logged.
+	c.Check(strings.Count(c.GetTestLog(), "obtained int"), check.Equals, 1)
+	c.Check(attempts > 1, check.Equals, true)
+}
+
+func (s *HelpersS) TestConsistentlySucceed(c *check.C) {
+	attempts := 0
+	testHelperSuccess(c, "Consistently(...)", true, func() interface{} {
+		return c.Consistently(func(c *check.C) {
+			attempts++
+			c.Assert(attempts > 0, check.Equals, true)
+		}, 20*time.Millisecond, time.Millisecond)
+	})
+	c.Check(attempts > 1, check.Equals, true)
+}
+
+func (s *HelpersS) TestConsistentlyFail(c *check.C) {
+	attempts := 0
+	log := "(?s)helpers_test\\.go:[0-9]+:.*\nhelpers_test\\.go:[0-9]+:\n" +
+		"    return c\\.Consistently\\(func\\(c \\*check\\.C\\) \\{\n.*" +
+		"\\.+ Consistently: failed within 1s, on attempt 3:\n" +
+		"helpers_test\\.go:[0-9]+:\n" +
+		"    c\\.Check\\(attempts < 3, check\\.Equals, true\\)\n" +
+		"\\.+ obtained bool = false\n" +
+		"\\.+ expected bool = true\n\n"
+	testHelperFailure(c, "Consistently(...)", false, false, log, func() interface{} {
+		return c.Consistently(func(c *check.C) {
+			attempts++
+			c.Check(attempts < 3, check.Equals, true)
+		}, time.Second, time.Millisecond)
+	})
+	c.Check(attempts, check.Equals, 3)
+}
+
+func (s *HelpersS) TestEventuallyPanic(c *check.C) {
+	defer func() {
+		c.Check(recover(), check.Equals, "boom")
+	}()
+	c.Eventually(func(c *check.C) {
+		panic("boom")
+	}, time.Second, time.Millisecond)
+	c.Fatal("Eventually didn't panic")
+}
+
+func (s *HelpersS) TestPollingRejectsRunParallelSkip(c *check.C) {
+	c.Check(func() {
+		c.Eventually(func(c *check.C) {
+			c.Run("sub", func(c *check.C) {})
+		}, time.Second, time.Millisecond)
+	}, check.PanicMatches, "Run called from Eventually")
+	c.Check(func() {
+		c.Consistently(func(c *check.C) {
+			c.Parallel()
+		}, time.Second, time.Millisecond)
+	}, check.PanicMatches, "Parallel called from Consistently")
+	c.Check(func() {
+		c.Eventually(func(c *check.C) {
+			c.Skip("not now")
+		}, time.Second, time.Millisecond)
+	}, check.PanicMatches, "Skip called from Eventually")
+}
+
 // -----------------------------------------------------------------------
 // A couple of helper functions to test helper functions. :-)
 
@@ -518,3 +611,90 @@ func testHelperFailure(c *check.C, name string, expectedResult interface{}, shou
 		c.Logf("%s didn't stop when it should", name)
 	}
 }
+
+// -----------------------------------------------------------------------
+// Ensure that differences are logged for failed comparisons.
+
+type diffItem struct {
+	Name string
+	Tags map[string]int
+}
+
+type diffList struct {
+	Items []diffItem
+	count int
+}
+
+func (s *HelpersS) TestDeepEqualsLogsDifference(c *check.C) {
+	obtained := &diffList{Items: []diffItem{{Name: "a"}, {Name: "b", Tags: map[string]int{"x": 1, "y": 2}}}, count: 2}
+	expected := &diffList{Items: []diffItem{{Name: "a"}, {Name: "c", Tags: map[string]int{"x": 1, "z": 3}}, {}}, count: 3}
+	log := "(?s)helpers_test\\.go:[0-9]+:.*\nhelpers_test\\.go:[0-9]+:\n" +
+		"    return c\\.Check\\(obtained, check\\.DeepEquals, expected\\)\n" +
+		"\\.+ obtained \\*check_test\\.diffList = .*\n" +
+		"\\.+ expected \\*check_test\\.diffList = .*\n" +
+		"\\.+ Difference:\n" +
+		"\\.+     \\.Items\\[1\\]\\.Name: \"b\" != \"c\"\n" +
+		"\\.+     \\.Items\\[1\\]\\.Tags\\[\"y\"\\]: 2 != <missing>\n" +
+		"\\.+     \\.Items\\[1\\]\\.Tags\\[\"z\"\\]: <missing> != 3\n" +
+		"\\.+     \\.Items\\[2\\]: <missing> != check_test\\.diffItem{Name:\"\", Tags:map\\[string\\]int\\(nil\\)}\n" +
+		"\\.+     \\.count: 2 != 3\n\n"
+	testHelperFailure(c, "Check(obtained, DeepEquals, expected)", false, false, log,
+		func() interface{} {
+			return c.Check(obtained, check.DeepEquals, expected)
+		})
+}
+
+type diffPoint struct {
+	X, Y int
+}
+
+func (s *HelpersS) TestEqualsLogsDifference(c *check.C) {
+	log := "(?s)helpers_test\\.go:[0-9]+:.*\nhelpers_test\\.go:[0-9]+:\n" +
+		"    return c\\.Check\\(diffPoint{1, 2}, check\\.Equals, diffPoint{1, 3}\\)\n" +
+		"\\.+ obtained check_test\\.diffPoint = check_test\\.diffPoint{X:1, Y:2}\n" +
+		"\\.+ expected check_test\\.diffPoint = check_test\\.diffPoint{X:1, Y:3}\n" +
+		"\\.+ Difference:\n" +
+		"\\.+     \\.Y: 2 != 3\n\n"
+	testHelperFailure(c, "Check(a, Equals, b)", false, false, log,
+		func() interface{} {
+			return c.Check(diffPoint{1, 2}, check.Equals, diffPoint{1, 3})
+		})
+}
+
+func (s *HelpersS) TestDiffNotLoggedForWholeValues(c *check.C) {
+	log := "(?s)helpers_test\\.go:[0-9]+:.*\nhelpers_test\\.go:[0-9]+:\n" +
+		"    return c\\.Check\\(1, check\\.DeepEquals, 2\\)\n" +
+		"\\.+ obtained int = 1\n" +
+		"\\.+ expected int = 2\n\n"
+	testHelperFailure(c, "Check(1, DeepEquals, 2)", false, false, log,
+		func() interface{} {
+			return c.Check(1, check.DeepEquals, 2)
+		})
+}
+
+func (s *HelpersS) TestMultiLineStringsLogDifference(c *check.C) {
+	obtained := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	expected := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	log := "(?s)helpers_test\\.go:[0-9]+:.*\nhelpers_test\\.go:[0-9]+:\n" +
+		"    return c\\.Check\\(obtained, check\\.Equals, expected\\)\n" +
+		"\\.+ obtained string = \"\" \\+\n.*" +
+		"\\.+ Difference:\n" +
+		"\\.+     --- obtained\n" +
+		"\\.+     \\+\\+\\+ expected\n" +
+		"\\.+     @@ -1,5 \\+1,5 @@\n" +
+		"\\.+      \"a\\\\n\"\n" +
+		"\\.+     -\"b\\\\n\"\n" +
+		"\\.+     \\+\"B\\\\n\"\n" +
+		"\\.+      \"c\\\\n\"\n" +
+		"\\.+      \"d\\\\n\"\n" +
+		"\\.+      \"e\\\\n\"\n" +
+		"\\.+     @@ -8,3 \\+8,4 @@\n" +
+		"\\.+      \"h\\\\n\"\n" +
+		"\\.+      \"i\\\\n\"\n" +
+		"\\.+      \"j\\\\n\"\n" +
+		"\\.+     \\+\"k\\\\n\"\n\n"
+	testHelperFailure(c, "Check(obtained, Equals, expected)", false, false, log,
+		func() interface{} {
+			return c.Check(obtained, check.Equals, expected)
+		})
+}
@@ -0,0 +1,118 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------
+// JSON event stream, in the format written by "go test -json".
+
+type jsonReport struct {
+	m       sync.Mutex
+	encoder *json.Encoder
+}
+
+// jsonEvent mirrors the events of cmd/test2json, with the test named as
+// "SuiteName.MethodName".
+type jsonEvent struct {
+	Time    time.Time
+	Action  string
+	Package string   `json:",omitempty"`
+	Test    string   `json:",omitempty"`
+	Elapsed *float64 `json:",omitempty"`
+	Output  string   `json:",omitempty"`
+}
+
+// Create a new report which will stream events into the given writer, or
+// nil if there's no writer to put them into.
+func newJSONReport(writer io.Writer) *jsonReport {
+	if writer == nil {
+		return nil
+	}
+	return &jsonReport{encoder: json.NewEncoder(writer)}
+}
+
+func (r *jsonReport) Report(e event, c *C) {
+	name := c.method.String() + c.subtestPath()
+	var action, label string
+	switch e {
+	case startTest:
+		if c.kind == testKd {
+			r.emit(c, "run", name, "", nil)
+			r.emit(c, "output", name, "=== RUN   "+name+"\n", nil)
+		}
+		return
+	case success, expectedFailure:
+		if c.kind != testKd {
+			return
+		}
+		action, label = "pass", "PASS"
+		if c.N > 0 {
+			action, label = "bench", "BENCH"
+		}
+	case skip:
+		if c.kind != testKd {
+			return
+		}
+		action, label = "skip", "SKIP"
+	case failure:
+		action, label = "fail", "FAIL"
+	case panicked:
+		action, label = "fail", "PANIC"
+	case missed:
+		action, label = "fail", "MISS"
+	default:
+		return
+	}
+	if c.retried {
+		action, label = "skip", "RETRY"
+	}
+	if c.kind != testKd {
+		// Fixtures have no start event of their own.
+		r.emit(c, "run", name, "", nil)
+	}
+	for _, line := range splitLines(c.logb.String()) {
+		r.emit(c, "output", name, line, nil)
+	}
+	header := fmt.Sprintf("--- %s: %s", label, name)
+	if c.reason != "" {
+		header += " (" + c.reason + ")"
+	}
+	if action == "bench" {
+		header += "\t" + c.timerString()
+	} else {
+		header += fmt.Sprintf(" (%.2fs)", c.elapsed.Seconds())
+	}
+	r.emit(c, "output", name, header+"\n", nil)
+	elapsed := c.elapsed.Seconds()
+	r.emit(c, action, name, "", &elapsed)
+}
+
+func (r *jsonReport) emit(c *C, action, test, output string, elapsed *float64) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.encoder.Encode(&jsonEvent{
+		Time:    time.Now(),
+		Action:  action,
+		Package: jsonPackage(c.method),
+		Test:    test,
+		Elapsed: elapsed,
+		Output:  output,
+	})
+}
+
+// The package of the suite, as "go test" names it. Suites defined in an
+// external test package are reported under the package they test.
+func jsonPackage(method *methodType) string {
+	t := method.Info.Type.In(0)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.TrimSuffix(t.PkgPath(), "_test")
+}
@@ -0,0 +1,93 @@
+package check_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/elopio/check"
+)
+
+var _ = Suite(&jsonS{})
+
+type jsonS struct{}
+
+type jsonEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed *float64
+	Output  string
+}
+
+func decodeJSONEvents(c *C, stream string) []jsonEvent {
+	var events []jsonEvent
+	decoder := json.NewDecoder(strings.NewReader(stream))
+	for decoder.More() {
+		var e jsonEvent
+		c.Assert(decoder.Decode(&e), IsNil)
+		events = append(events, e)
+	}
+	return events
+}
+
+func (s *jsonS) TestJSONOutput(c *C) {
+	output := String{}
+	stream := String{}
+	Run(&JUnitHelper{}, &RunConf{Output: &output, JSONOutput: &stream})
+
+	var actions, outputs []string
+	for _, e := range decodeJSONEvents(c, stream.value) {
+		c.Check(e.Package, Equals, "github.com/elopio/check")
+		if e.Action == "output" {
+			outputs = append(outputs, e.Output)
+			continue
+		}
+		actions = append(actions, e.Action+" "+e.Test)
+		c.Check(e.Elapsed != nil, Equals, e.Action != "run", Commentf("%s %s", e.Action, e.Test))
+	}
+	c.Check(actions, DeepEquals, []string{
+		"run JUnitHelper.TestFail",
+		"fail JUnitHelper.TestFail",
+		"run JUnitHelper.TestPanic",
+		"fail JUnitHelper.TestPanic",
+		"run JUnitHelper.TestSkip",
+		"skip JUnitHelper.TestSkip",
+		"run JUnitHelper.TestSucceed",
+		"pass JUnitHelper.TestSucceed",
+	})
+	c.Check(strings.Join(outputs, ""), Matches, ""+
+		"=== RUN   JUnitHelper.TestFail\n"+
+		"Expected failure!\n"+
+		"--- FAIL: JUnitHelper.TestFail \\([0-9.]+s\\)\n"+
+		"=== RUN   JUnitHelper.TestPanic\n"+
+		"\\.\\.\\. Panic: BOOM (.|\n)*"+
+		"--- PANIC: JUnitHelper.TestPanic \\([0-9.]+s\\)\n"+
+		"=== RUN   JUnitHelper.TestSkip\n"+
+		"--- SKIP: JUnitHelper.TestSkip \\(No reason\\) \\([0-9.]+s\\)\n"+
+		"=== RUN   JUnitHelper.TestSucceed\n"+
+		"--- PASS: JUnitHelper.TestSucceed \\([0-9.]+s\\)\n")
+
+	// The console output is not affected.
+	c.Check(output.value, Matches, "(?s).*FAIL: junit_test.go:[0-9]+: JUnitHelper.TestFail\n\nExpected failure!\n.*")
+}
+
+func (s *jsonS) TestJSONOutputFixturePanic(c *C) {
+	output := String{}
+	stream := String{}
+	Run(&FixtureHelper{panicOn: "SetUpSuite"}, &RunConf{Output: &output, JSONOutput: &stream})
+
+	var actions []string
+	for _, e := range decodeJSONEvents(c, stream.value) {
+		if e.Action != "output" {
+			actions = append(actions, e.Action+" "+e.Test)
+		}
+	}
+	c.Check(actions, DeepEquals, []string{
+		"run FixtureHelper.SetUpSuite",
+		"fail FixtureHelper.SetUpSuite",
+		"run FixtureHelper.Test1",
+		"fail FixtureHelper.Test1",
+		"run FixtureHelper.Test2",
+		"fail FixtureHelper.Test2",
+	})
+}
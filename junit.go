@@ -0,0 +1,137 @@
+package check
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------
+// JUnit XML report, collecting the calls of all the suites run.
+
+type junitReport struct {
+	m      sync.Mutex
+	writer io.Writer
+	suites []*junitSuite
+}
+
+type junitSuites struct {
+	XMLName xml.Name      `xml:"testsuites"`
+	Suites  []*junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Cases    []*junitCase `xml:"testcase"`
+
+	duration time.Duration
+}
+
+type junitCase struct {
+	Classname string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitProblem `xml:"skipped,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// Create a new report which will be written into the given writer, or
+// nil if there's no writer to put it into.
+func newJUnitReport(writer io.Writer) *junitReport {
+	if writer == nil {
+		return nil
+	}
+	return &junitReport{writer: writer}
+}
+
+func (r *junitReport) Report(e event, c *C) {
+	if c.retried {
+		// Only the last attempt of a test is reported.
+		return
+	}
+	switch e {
+	case startTest:
+		return
+	case success, expectedFailure, skip:
+		// Fixtures are only interesting when they break, and a skipped
+		// fixture will have its tests reported as skipped as well.
+		if c.kind != testKd {
+			return
+		}
+	}
+
+	duration := c.elapsed
+	suiteName := c.method.suiteName()
+	tc := &junitCase{
+		Classname: suiteName,
+		Name:      c.method.Info.Name + c.subtestPath(),
+		Time:      junitTime(duration),
+	}
+	switch e {
+	case failure:
+		tc.Failure = &junitProblem{Message: "Failed", Body: c.logb.String()}
+	case panicked:
+		tc.Error = &junitProblem{Message: "Panicked", Body: c.logb.String()}
+	case missed:
+		tc.Error = &junitProblem{Message: "Missed", Body: c.logb.String()}
+	case skip:
+		tc.Skipped = &junitProblem{Message: c.reason, Body: c.logb.String()}
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	var suite *junitSuite
+	if n := len(r.suites); n > 0 && r.suites[n-1].Name == suiteName {
+		suite = r.suites[n-1]
+	} else {
+		suite = &junitSuite{Name: suiteName}
+		r.suites = append(r.suites, suite)
+	}
+	suite.Cases = append(suite.Cases, tc)
+	suite.Tests++
+	switch {
+	case tc.Failure != nil:
+		suite.Failures++
+	case tc.Error != nil:
+		suite.Errors++
+	case tc.Skipped != nil:
+		suite.Skipped++
+	}
+	suite.duration += duration
+	suite.Time = junitTime(suite.duration)
+}
+
+// Write the report with all the suites seen so far. Does nothing on a
+// nil report.
+func (r *junitReport) write() error {
+	if r == nil {
+		return nil
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	data, err := xml.MarshalIndent(&junitSuites{Suites: r.suites}, "", "  ")
+	if err == nil {
+		_, err = fmt.Fprintf(r.writer, "%s%s\n", xml.Header, data)
+	}
+	if err != nil {
+		return fmt.Errorf("Can't write JUnit report: %v", err)
+	}
+	return nil
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
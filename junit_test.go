@@ -0,0 +1,91 @@
+package check_test
+
+import (
+	"encoding/xml"
+
+	. "github.com/elopio/check"
+)
+
+var _ = Suite(&junitS{})
+
+type junitS struct{}
+
+type JUnitHelper struct{}
+
+func (s *JUnitHelper) TestFail(c *C) {
+	c.Log("Expected failure!")
+	c.Fail()
+}
+
+func (s *JUnitHelper) TestPanic(c *C) {
+	panic("BOOM")
+}
+
+func (s *JUnitHelper) TestSkip(c *C) {
+	c.Skip("No reason")
+}
+
+func (s *JUnitHelper) TestSucceed(c *C) {
+}
+
+func (s *junitS) TestReport(c *C) {
+	output := String{}
+	report := String{}
+	result := Run(&JUnitHelper{}, &RunConf{Output: &output, JUnitOutput: &report})
+	c.Assert(result.Passed(), Equals, false)
+
+	expected := `<\?xml version="1.0" encoding="UTF-8"\?>
+<testsuites>
+  <testsuite name="JUnitHelper" tests="4" failures="1" errors="1" skipped="1" time="[0-9]+\.[0-9]{3}">
+    <testcase classname="JUnitHelper" name="TestFail" time="[0-9]+\.[0-9]{3}">
+      <failure message="Failed">Expected failure!&#xA;</failure>
+    </testcase>
+    <testcase classname="JUnitHelper" name="TestPanic" time="[0-9]+\.[0-9]{3}">
+      <error message="Panicked">\.\.\. Panic: BOOM .*</error>
+    </testcase>
+    <testcase classname="JUnitHelper" name="TestSkip" time="[0-9]+\.[0-9]{3}">
+      <skipped message="No reason"></skipped>
+    </testcase>
+    <testcase classname="JUnitHelper" name="TestSucceed" time="[0-9]+\.[0-9]{3}"></testcase>
+  </testsuite>
+</testsuites>
+`
+	c.Assert(report.value, Matches, "(?s)"+expected)
+
+	// The console output is not affected.
+	c.Assert(output.value, Matches, "(?s).*FAIL: junit_test.go:[0-9]+: JUnitHelper.TestFail\n\nExpected failure!\n.*")
+}
+
+func (s *junitS) TestReportFixturePanic(c *C) {
+	output := String{}
+	report := String{}
+	Run(&FixtureHelper{panicOn: "SetUpSuite"}, &RunConf{Output: &output, JUnitOutput: &report})
+
+	var doc struct {
+		Suites []struct {
+			Name   string `xml:"name,attr"`
+			Tests  int    `xml:"tests,attr"`
+			Errors int    `xml:"errors,attr"`
+			Cases  []struct {
+				Name  string `xml:"name,attr"`
+				Error *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"error"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	c.Assert(xml.Unmarshal([]byte(report.value), &doc), IsNil)
+	c.Assert(doc.Suites, HasLen, 1)
+	suite := doc.Suites[0]
+	c.Check(suite.Name, Equals, "FixtureHelper")
+	c.Check(suite.Tests, Equals, 3)
+	c.Check(suite.Errors, Equals, 3)
+	c.Assert(suite.Cases, HasLen, 3)
+	var names, messages []string
+	for _, tc := range suite.Cases {
+		names = append(names, tc.Name)
+		messages = append(messages, tc.Error.Message)
+	}
+	c.Check(names, DeepEquals, []string{"SetUpSuite", "Test1", "Test2"})
+	c.Check(messages, DeepEquals, []string{"Panicked", "Missed", "Missed"})
+}
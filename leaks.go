@@ -0,0 +1,72 @@
+package check
+
+import (
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------
+// Detection of goroutines leaked by tests.
+
+// Goroutines created by the runner itself are never leaks.
+var defaultLeakAllowlist = []string{
+	"check.(*suiteRunner).startCall",
+}
+
+// Return the stacks of all goroutines, by goroutine id.
+func goroutineStacks() map[string]string {
+	stacks := make(map[string]string)
+	for _, stack := range strings.Split(strings.TrimSpace(string(allStacks())), "\n\n") {
+		// Stacks start with a line such as "goroutine 7 [running]:".
+		fields := strings.Fields(stack)
+		if len(fields) > 1 && fields[0] == "goroutine" {
+			stacks[fields[1]] = stack
+		}
+	}
+	return stacks
+}
+
+// Return the stacks of the goroutines which weren't running before, and
+// aren't in the allowlist.
+func (runner *suiteRunner) leakedGoroutines(before map[string]string) []string {
+	var leaks []string
+	for id, stack := range goroutineStacks() {
+		if _, ok := before[id]; ok || runner.leakAllowed(stack) {
+			continue
+		}
+		leaks = append(leaks, stack)
+	}
+	return leaks
+}
+
+func (runner *suiteRunner) leakAllowed(stack string) bool {
+	for _, allowed := range runner.leakAllowlist {
+		if strings.Contains(stack, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fail the test if, once done with its fixtures and cleanups, it left
+// behind goroutines which are still running after the grace period.
+// Tests which failed already aren't checked, and neither are tests
+// running in parallel, since their goroutines can't be told apart.
+func (runner *suiteRunner) checkLeaks(c *C, before map[string]string) {
+	if c.parallel || c.status() != succeededSt {
+		return
+	}
+	deadline := time.Now().Add(runner.leakGrace)
+	for {
+		leaks := runner.leakedGoroutines(before)
+		if len(leaks) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			c.logLeaks(leaks)
+			c.setStatus(failedSt)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
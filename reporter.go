@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"time"
 )
 
 type testReporter interface {
@@ -22,6 +23,148 @@ const (
 	missed
 )
 
+// -----------------------------------------------------------------------
+// Public reporting interface.
+
+// Reporter must be implemented by values provided in RunConf.Reporters
+// to be notified about the progress of the run. Report is called once
+// when a suite method starts, and once when it finishes. When tests run
+// in parallel, Report may be called concurrently.
+type Reporter interface {
+	Report(event *Event)
+}
+
+// EventType tells what happened to the suite method in an Event.
+type EventType int
+
+const (
+	EventStart EventType = iota
+	EventPass
+	EventFail
+	EventPanic
+	EventExpectedFailure
+	EventSkip
+	EventMiss
+)
+
+var eventTypeLabels = []string{
+	EventStart:           "START",
+	EventPass:            "PASS",
+	EventFail:            "FAIL",
+	EventPanic:           "PANIC",
+	EventExpectedFailure: "FAIL EXPECTED",
+	EventSkip:            "SKIP",
+	EventMiss:            "MISS",
+}
+
+// String returns the label used for the event type in the console output.
+func (t EventType) String() string {
+	if t >= 0 && int(t) < len(eventTypeLabels) {
+		return eventTypeLabels[t]
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event describes a suite method starting or finishing.
+type Event struct {
+	Type     EventType
+	Suite    string        // Name of the suite type.
+	Method   string        // Name of the test or fixture method.
+	Fixture  bool          // Whether the method is a fixture.
+	Duration time.Duration // How long the call took, zero when starting.
+	Log      string        // The call log, empty when starting.
+	Reason   string        // Why the call was skipped or expected to fail.
+
+	// Benchmark is only set when a benchmark finishes successfully.
+	Benchmark *BenchmarkResult
+}
+
+// Name returns the method name in the form "SuiteName.MethodName".
+func (e *Event) Name() string {
+	return e.Suite + "." + e.Method
+}
+
+// BenchmarkResult holds the measurements of a benchmark.
+type BenchmarkResult struct {
+	N                 int           // Number of iterations.
+	T                 time.Duration // Total time taken by the iterations.
+	NsPerOp           int64
+	MBPerSec          float64 // Zero unless SetBytes was called.
+	AllocsPerOp       int64   // Zero unless memory benchmarks are on.
+	AllocedBytesPerOp int64   // Zero unless memory benchmarks are on.
+}
+
+var eventTypes = map[event]EventType{
+	startTest:       EventStart,
+	failure:         EventFail,
+	panicked:        EventPanic,
+	success:         EventPass,
+	expectedFailure: EventExpectedFailure,
+	skip:            EventSkip,
+	missed:          EventMiss,
+}
+
+func newEvent(e event, c *C) *Event {
+	ev := &Event{
+		Type:    eventTypes[e],
+		Suite:   c.method.suiteName(),
+		Method:  c.method.Info.Name + c.subtestPath(),
+		Fixture: c.kind == fixtureKd,
+		Reason:  c.reason,
+	}
+	if e != startTest {
+		ev.Duration = c.elapsed
+		ev.Log = c.logb.String()
+	}
+	if e == success && c.N > 0 {
+		ev.Benchmark = &BenchmarkResult{
+			N:        c.N,
+			T:        c.duration,
+			NsPerOp:  c.nsPerOp(),
+			MBPerSec: c.mbPerSec(),
+		}
+		if c.benchMem {
+			ev.Benchmark.AllocsPerOp = int64(c.netAllocs) / int64(c.N)
+			ev.Benchmark.AllocedBytesPerOp = int64(c.netBytes) / int64(c.N)
+		}
+	}
+	return ev
+}
+
+func newTestResult(ev *Event) *TestResult {
+	return &TestResult{
+		Suite:     ev.Suite,
+		Method:    ev.Method,
+		Status:    ev.Type,
+		Duration:  ev.Duration,
+		Reason:    ev.Reason,
+		Log:       ev.Log,
+		Benchmark: ev.Benchmark,
+	}
+}
+
+// publicReporters adapts the reporters provided in RunConf to the internal
+// reporting interface. All of them are handed the same Event.
+type publicReporters []Reporter
+
+func (pr publicReporters) Report(e event, c *C) {
+	ev := newEvent(e, c)
+	for _, r := range pr {
+		r.Report(ev)
+	}
+}
+
+// -----------------------------------------------------------------------
+// Multi reporter forwards events to several reporters, in order.
+
+type multiReporter []testReporter
+
+func (mr multiReporter) Report(e event, c *C) {
+	for _, r := range mr {
+		r.Report(e, c)
+	}
+}
+
 // -----------------------------------------------------------------------
 // Output writer manages atomic output writing according to settings.
 
@@ -45,7 +188,11 @@ func (ow *outputWriter) Report(e event, c *C) {
 	case panicked:
 		ow.writeProblem("PANIC", c)
 	case success:
-		ow.writeSuccess("PASS", c)
+		if c.attempt > 0 {
+			ow.writeSuccess("FLAKY", c)
+		} else {
+			ow.writeSuccess("PASS", c)
+		}
 	case expectedFailure:
 		ow.writeSuccess("FAIL EXPECTED", c)
 	case skip:
@@ -108,8 +255,29 @@ func (ow *outputWriter) writeSuccess(label string, c *C) {
 	}
 }
 
+func (ow *outputWriter) writePassRatio(method *methodType, passed, flaky, total int) {
+	if ow.verbosity > 0 || passed != total {
+		suffix := fmt.Sprintf("\t%d/%d passed", passed, total)
+		if flaky > 0 {
+			suffix += fmt.Sprintf(", %d flaky", flaky)
+		}
+		suffix += "\n"
+		if ow.verbosity > 1 {
+			suffix += "\n"
+		}
+		header := renderMethodHeader("RATIO", method, "", "", suffix)
+		ow.m.Lock()
+		ow.writer.Write([]byte(header))
+		ow.m.Unlock()
+	}
+}
+
 func renderCallHeader(label string, c *C, prefix, suffix string) string {
-	pc := c.method.PC()
-	return fmt.Sprintf("%s%s: %s: %s%s", prefix, label, niceFuncPath(pc),
-		niceFuncName(pc), suffix)
+	return renderMethodHeader(label, c.method, c.subtestPath(), prefix, suffix)
+}
+
+func renderMethodHeader(label string, method *methodType, subtest, prefix, suffix string) string {
+	pc := method.PC()
+	return fmt.Sprintf("%s%s: %s: %s%s%s", prefix, label, niceFuncPath(pc),
+		niceFuncName(pc), subtest, suffix)
 }
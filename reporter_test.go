@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"runtime"
+	"sync"
 
 	. "github.com/elopio/check"
 )
@@ -171,3 +172,88 @@ func (s *reporterS) TestAddSuccessWithoutVerbosity(c *C) {
 		c.Check(output.value, Equals, "")
 	}
 }
+
+// -----------------------------------------------------------------------
+// Public reporters provided in RunConf.
+
+type eventRecorder struct {
+	m      sync.Mutex
+	events []*Event
+}
+
+func (r *eventRecorder) Report(e *Event) {
+	r.m.Lock()
+	r.events = append(r.events, e)
+	r.m.Unlock()
+}
+
+func (s *reporterS) TestReporters(c *C) {
+	output := String{}
+	r1 := &eventRecorder{}
+	r2 := &eventRecorder{}
+	helper := &FixtureHelper{panicOn: "Test1"}
+	Run(helper, &RunConf{Output: &output, Reporters: []Reporter{r1, r2}})
+
+	c.Assert(r1.events, DeepEquals, r2.events)
+	var names []string
+	for _, e := range r1.events {
+		names = append(names, e.Type.String()+" "+e.Name())
+	}
+	c.Assert(names, DeepEquals, []string{
+		"START FixtureHelper.SetUpSuite",
+		"PASS FixtureHelper.SetUpSuite",
+		"START FixtureHelper.Test1",
+		"START FixtureHelper.SetUpTest",
+		"PASS FixtureHelper.SetUpTest",
+		"START FixtureHelper.TearDownTest",
+		"PASS FixtureHelper.TearDownTest",
+		"PANIC FixtureHelper.Test1",
+		"START FixtureHelper.Test2",
+		"START FixtureHelper.SetUpTest",
+		"PASS FixtureHelper.SetUpTest",
+		"START FixtureHelper.TearDownTest",
+		"PASS FixtureHelper.TearDownTest",
+		"PASS FixtureHelper.Test2",
+		"START FixtureHelper.TearDownSuite",
+		"PASS FixtureHelper.TearDownSuite",
+	})
+
+	panicEvent := r1.events[7]
+	c.Check(panicEvent.Suite, Equals, "FixtureHelper")
+	c.Check(panicEvent.Method, Equals, "Test1")
+	c.Check(panicEvent.Fixture, Equals, false)
+	c.Check(panicEvent.Benchmark, IsNil)
+	c.Check(panicEvent.Log, Matches, "(?s)\\.\\.\\. Panic: Test1 .*")
+	c.Check(r1.events[0].Fixture, Equals, true)
+
+	// The console output still gets the call log.
+	c.Check(output.value, Matches, "(?s).*PANIC: check_test\\.go:[0-9]+: FixtureHelper\\.Test1\n\n\\.\\.\\. Panic: Test1 .*")
+}
+
+func (s *reporterS) TestReporterSkipAndBenchmark(c *C) {
+	output := String{}
+	r := &eventRecorder{}
+	Run(&SkipTestHelper{}, &RunConf{Output: &output, Reporters: []Reporter{r}})
+	c.Assert(r.events, HasLen, 2)
+	c.Check(r.events[1].Type, Equals, EventSkip)
+	c.Check(r.events[1].Reason, Equals, "Wrong platform or whatever")
+
+	r = &eventRecorder{}
+	helper := &FixtureHelper{sleep: 100000}
+	runConf := &RunConf{
+		Output:        &output,
+		Benchmark:     true,
+		BenchmarkTime: 10000000,
+		BenchmarkMem:  true,
+		Filter:        "Benchmark3",
+		Reporters:     []Reporter{r},
+	}
+	Run(helper, runConf)
+	e := r.events[len(r.events)-3]
+	c.Assert(e.Name(), Equals, "FixtureHelper.Benchmark3")
+	c.Assert(e.Type, Equals, EventPass)
+	c.Assert(e.Benchmark, NotNil)
+	c.Check(e.Benchmark.N > 0, Equals, true)
+	c.Check(e.Benchmark.NsPerOp > 0, Equals, true)
+	c.Check(e.Benchmark.AllocsPerOp > 0, Equals, true)
+}
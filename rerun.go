@@ -0,0 +1,111 @@
+package check
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// -----------------------------------------------------------------------
+// Record of the tests which failed, for running just those again.
+
+// Return the file the failed tests are recorded in by default, which is
+// one in the user's cache directory for each working directory, and so
+// for each package being tested.
+func defaultStatePath() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = "."
+	}
+	h := fnv.New32a()
+	h.Write([]byte(dir))
+	return filepath.Join(cache, "check", fmt.Sprintf("failed-%08x", h.Sum32())), nil
+}
+
+// Read the names of the failed tests recorded in the given file. A file
+// which doesn't exist records no failures.
+func readFailedTests(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, scanner.Err()
+}
+
+// Update the failed tests recorded in the given file with the outcome of
+// the tests in result. Tests which didn't run keep their earlier record.
+func writeFailedTests(path string, result *Result) error {
+	old, err := readFailedTests(path)
+	if err != nil {
+		return err
+	}
+	failed := make(map[string]bool)
+	for _, name := range old {
+		failed[name] = true
+	}
+	for _, test := range result.Tests {
+		delete(failed, test.Name())
+	}
+	for _, name := range result.FailedTests() {
+		failed[name] = true
+	}
+	var names []string
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var data string
+	for _, name := range names {
+		data += name + "\n"
+	}
+	// Write a new file and move it in place, rather than writing through
+	// whatever is at path already.
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(dir, ".check-failed-")
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+// Return a filter selecting exactly the tests with the given names.
+func failedFilter(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
@@ -4,7 +4,14 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
 	"os"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -42,73 +49,295 @@ var (
 	newBenchMem    = flag.Bool("check.bmem", false, "Report memory benchmarks")
 	newListFlag    = flag.Bool("check.list", false, "List the names of all tests that will be run")
 	newWorkFlag    = flag.Bool("check.work", false, "Display and do not remove the test working directory")
+	newParallel    = flag.Int("check.parallel", runtime.GOMAXPROCS(0), "Maximum number of parallel tests to run at once within a suite")
+	newTimeout     = flag.Duration("check.timeout", 0, "Fail any test running longer than this and dump its goroutines (0 to disable)")
+	newJUnitFlag   = flag.String("check.junit", "", "Write a JUnit XML report into the given file")
+	newShuffleFlag = flag.String("check.shuffle", "off", "Randomize the order of suites and tests: off, on, or the seed to use")
+	newFailFast    = flag.Bool("check.failfast", false, "Do not start new tests after the first test failure")
+	newJSONFlag    = flag.Bool("check.json", false, "Write events to stdout as JSON, in the format of go test -json")
+	newCountFlag   = flag.Int("check.count", 1, "Run each test this many times, and report how often it passed")
+	newRetryFlag   = flag.Int("check.retry", 0, "Run failed tests again up to this many times, counting them as flaky if they pass (tests calling C.Parallel are never retried)")
+	newExcludeFlag = flag.String("check.exclude", "", "Regular expression selecting which tests and/or suites not to run")
+	newTagsFlag    = flag.String("check.tags", "", "Expression on tags selecting which tests to run, such as 'integration && !slow'")
+	newLeaksFlag   = flag.Bool("check.leaks", false, "Fail tests which leave goroutines running after they're done")
+	newStateFlag   = flag.String("check.state", "", "File recording the tests which failed, for -check.rerun-failed (defaults to one in the user cache directory)")
+	newRerunFlag   = flag.Bool("check.rerun-failed", false, "Run only the tests which failed when last run")
+	newShardFlag   = flag.String("check.shard", "", "Run only the tests of shard i out of n, given as i/n")
+	newShardTiming = flag.String("check.shard-timing", "", "Balance the shards with the test times in this -check.json output of a previous run")
 )
 
 // TestingT runs all test suites registered with the Suite function,
 // printing results to stdout, and reporting any failures back to
 // the "testing" package.
 func TestingT(testingT *testing.T) {
+	conf := flagsRunConf(testingT)
+	if listFlag(conf) {
+		return
+	}
+	if file := createJUnitFlag(testingT, conf); file != nil {
+		defer file.Close()
+	}
+	if *newJSONFlag {
+		conf.Output = ioutil.Discard
+		conf.JSONOutput = os.Stdout
+	}
+	result := RunAll(conf)
+	recordFailedTests(testingT, result)
+	println(result.String())
+	if !result.Passed() {
+		testingT.Fail()
+	}
+}
+
+// TestingSubtests runs all test suites registered with the Suite function
+// like TestingT does, but with each suite run as a subtest of testingT,
+// and each of its tests as a subtest of the suite, named "Suite/Method",
+// with the subtests started by C.Run nested under them.
+// This way the "testing" package reports every test on its own, and its
+// flags such as -run, -v and -json apply to them. Problems in the suite
+// fixtures are reported as failures of the suite subtest. The -check.json
+// flag can't be used, since "go test -json" reports the subtests already.
+func TestingSubtests(testingT *testing.T) {
+	conf := flagsRunConf(testingT)
+	if listFlag(conf) {
+		return
+	}
+	if *newJSONFlag {
+		testingT.Fatal("Can't use -check.json with TestingSubtests, use go test -json instead")
+	}
+	if file := createJUnitFlag(testingT, conf); file != nil {
+		defer file.Close()
+	}
+	conf.Output = ioutil.Discard
+	suites, conf := shuffleSuites(conf)
+	junit := newJUnitReport(junitOutput(conf))
+	all := Result{}
+	failed := false
+	for _, runner := range newSuiteRunners(suites, conf) {
+		runner := runner
+		testingT.Run(suiteTypeName(reflect.TypeOf(runner.suite)), func(t *testing.T) {
+			runner.testingT = t
+			runner.addReporter(testingReporter{t})
+			result := runSuite(runner, junit, failed)
+			if result.RunError != nil {
+				t.Error(result.RunError)
+			}
+			all.Add(result)
+			if conf.FailFast && !result.Passed() {
+				failed = true
+			}
+		})
+	}
+	if err := junit.write(); err != nil {
+		testingT.Errorf("Can't write JUnit report: %v", err)
+	}
+	recordFailedTests(testingT, &all)
+}
+
+// Create the file given with -check.junit, if any, and have the JUnit
+// report written into it. The caller must close the returned file.
+func createJUnitFlag(testingT *testing.T, conf *RunConf) *os.File {
+	if *newJUnitFlag == "" {
+		return nil
+	}
+	file, err := os.Create(*newJUnitFlag)
+	if err != nil {
+		testingT.Fatalf("Can't create JUnit report: %v", err)
+	}
+	conf.JUnitOutput = file
+	return file
+}
+
+// Record the tests which failed in the run, for -check.rerun-failed.
+func recordFailedTests(testingT *testing.T, result *Result) {
+	if result.RunError != nil {
+		return
+	}
+	path, err := statePath()
+	if err == nil {
+		err = writeFailedTests(path, result)
+	}
+	if err != nil {
+		// Not being able to record them doesn't make the run fail.
+		fmt.Fprintf(os.Stderr, "WARNING: Can't record the failed tests: %v\n", err)
+	}
+}
+
+func statePath() (string, error) {
+	if *newStateFlag != "" {
+		return *newStateFlag, nil
+	}
+	return defaultStatePath()
+}
+
+// Build the run configuration from the command line flags.
+func flagsRunConf(testingT *testing.T) *RunConf {
 	benchTime := *newBenchTime
 	if benchTime == 1*time.Second {
 		benchTime = *oldBenchTime
 	}
 	conf := &RunConf{
 		Filter:        *oldFilterFlag + *newFilterFlag,
+		Exclude:       *newExcludeFlag,
+		Tags:          *newTagsFlag,
 		Verbose:       *oldVerboseFlag || *newVerboseFlag,
 		Stream:        *oldStreamFlag || *newStreamFlag,
 		Benchmark:     *oldBenchFlag || *newBenchFlag,
 		BenchmarkTime: benchTime,
 		BenchmarkMem:  *newBenchMem,
 		KeepWorkDir:   *oldWorkFlag || *newWorkFlag,
+		Parallelism:   *newParallel,
+		TestTimeout:   *newTimeout,
+		FailFast:      *newFailFast,
+		Count:         *newCountFlag,
+		Retry:         *newRetryFlag,
+		DetectLeaks:   *newLeaksFlag,
 	}
-	if *oldListFlag || *newListFlag {
-		w := bufio.NewWriter(os.Stdout)
-		for _, name := range ListAll(conf) {
-			fmt.Fprintln(w, name)
+	switch *newShuffleFlag {
+	case "off":
+	case "on":
+		conf.Shuffle = true
+	default:
+		seed, err := strconv.ParseInt(*newShuffleFlag, 10, 64)
+		if err != nil {
+			testingT.Fatalf("Bad -check.shuffle value %q: must be off, on, or a seed", *newShuffleFlag)
 		}
-		w.Flush()
-		return
+		conf.Shuffle = true
+		conf.ShuffleSeed = seed
 	}
-	result := RunAll(conf)
-	println(result.String())
-	if !result.Passed() {
-		testingT.Fail()
+	if *newRerunFlag {
+		if conf.Filter != "" {
+			testingT.Fatalf("Can't use -check.rerun-failed together with -check.f")
+		}
+		var names []string
+		path, err := statePath()
+		if err == nil {
+			names, err = readFailedTests(path)
+		}
+		if err != nil {
+			testingT.Fatalf("Can't read the failed tests: %v", err)
+		}
+		conf.Filter = failedFilter(names)
+	}
+	if *newShardFlag != "" {
+		_, err := fmt.Sscanf(*newShardFlag, "%d/%d", &conf.Shard, &conf.Shards)
+		if err != nil || conf.Shards < 1 {
+			testingT.Fatalf("Bad -check.shard value %q: must be i/n", *newShardFlag)
+		}
+	}
+	if *newShardTiming != "" {
+		file, err := os.Open(*newShardTiming)
+		if err != nil {
+			testingT.Fatalf("Can't open shard timings: %v", err)
+		}
+		defer file.Close()
+		conf.ShardTimings, err = ReadShardTimings(file)
+		if err != nil {
+			testingT.Fatal(err)
+		}
+	}
+	return conf
+}
+
+// Print the names of the tests to be run if asked to, and return whether
+// that's all that should be done.
+func listFlag(conf *RunConf) bool {
+	if !*oldListFlag && !*newListFlag {
+		return false
+	}
+	w := bufio.NewWriter(os.Stdout)
+	for _, name := range ListAll(conf) {
+		fmt.Fprintln(w, name)
 	}
+	w.Flush()
+	return true
 }
 
 // RunAll runs all test suites registered with the Suite function, using the
 // provided run configuration.
 func RunAll(runConf *RunConf) *Result {
 	result := Result{}
-	for _, suite := range allSuites {
-		result.Add(Run(suite, runConf))
+	suites, runConf := shuffleSuites(runConf)
+	junit := newJUnitReport(junitOutput(runConf))
+	failed := false
+	for _, runner := range newSuiteRunners(suites, runConf) {
+		suiteResult := runSuite(runner, junit, failed)
+		result.Add(suiteResult)
+		if runConf != nil && runConf.FailFast && !suiteResult.Passed() {
+			failed = true
+		}
+	}
+	if err := junit.write(); err != nil && result.RunError == nil {
+		result.RunError = err
 	}
 	return &result
 }
 
 // Run runs the provided test suite using the provided run configuration.
 func Run(suite interface{}, runConf *RunConf) *Result {
-	runner := newSuiteRunner(suite, runConf)
+	junit := newJUnitReport(junitOutput(runConf))
+	runner := newSuiteRunners([]interface{}{suite}, runConf)[0]
+	result := runSuite(runner, junit, false)
+	if err := junit.write(); err != nil && result.RunError == nil {
+		result.RunError = err
+	}
+	return result
+}
+
+// Run the given suite, or only report its tests as missed if an earlier
+// suite has already failed in fail-fast mode.
+func runSuite(runner *suiteRunner, junit *junitReport, failed bool) *Result {
+	if junit != nil {
+		runner.addReporter(junit)
+	}
+	if failed {
+		return runner.runNone()
+	}
 	return runner.run()
 }
 
+// Return the registered suites in the order they should run, and the run
+// configuration to run them with. When shuffling, the same seed is used
+// for all suites so that the whole run may be reproduced.
+func shuffleSuites(runConf *RunConf) ([]interface{}, *RunConf) {
+	if runConf == nil || !runConf.Shuffle {
+		return allSuites, runConf
+	}
+	conf := *runConf
+	conf.ShuffleSeed = shuffleSeed(&conf)
+	suites := append([]interface{}{}, allSuites...)
+	rand.New(rand.NewSource(conf.ShuffleSeed)).Shuffle(len(suites), func(i, j int) {
+		suites[i], suites[j] = suites[j], suites[i]
+	})
+	return suites, &conf
+}
+
+func junitOutput(runConf *RunConf) io.Writer {
+	if runConf == nil {
+		return nil
+	}
+	return runConf.JUnitOutput
+}
+
 // ListAll returns the names of all the test functions registered with the
 // Suite function that will be run with the provided run configuration.
 func ListAll(runConf *RunConf) []string {
-	var names []string
-	for _, suite := range allSuites {
-		names = append(names, List(suite, runConf)...)
-	}
-	return names
+	suites, runConf := shuffleSuites(runConf)
+	return listTests(newSuiteRunners(suites, runConf))
 }
 
 // List returns the names of the test functions in the given
 // suite that will be run with the provided run configuration.
 func List(suite interface{}, runConf *RunConf) []string {
+	return listTests(newSuiteRunners([]interface{}{suite}, runConf))
+}
+
+func listTests(runners []*suiteRunner) []string {
 	var names []string
-	runner := newSuiteRunner(suite, runConf)
-	for _, t := range runner.tests {
-		names = append(names, t.String())
+	for _, runner := range runners {
+		for _, t := range runner.tests {
+			names = append(names, t.String())
+		}
 	}
 	return names
 }
@@ -124,6 +353,11 @@ func (r *Result) Add(other *Result) {
 	r.FixturePanicked += other.FixturePanicked
 	r.ExpectedFailures += other.ExpectedFailures
 	r.Missed += other.Missed
+	r.Flaky += other.Flaky
+	r.Tests = append(r.Tests, other.Tests...)
+	if r.ShuffleSeed == 0 {
+		r.ShuffleSeed = other.ShuffleSeed
+	}
 	if r.WorkDir != "" && other.WorkDir != "" {
 		r.WorkDir += ":" + other.WorkDir
 	} else if other.WorkDir != "" {
@@ -131,6 +365,22 @@ func (r *Result) Add(other *Result) {
 	}
 }
 
+// FailedTests returns the names of the tests which failed, panicked or
+// were missed, in the form "SuiteName.MethodName".
+func (r *Result) FailedTests() []string {
+	var names []string
+	for _, test := range r.Tests {
+		if test.Retried || strings.Contains(test.Method, "/") {
+			continue
+		}
+		switch test.Status {
+		case EventFail, EventPanic, EventMiss:
+			names = append(names, test.Name())
+		}
+	}
+	return names
+}
+
 func (r *Result) Passed() bool {
 	return (r.Failed == 0 && r.Panicked == 0 &&
 		r.FixturePanicked == 0 && r.Missed == 0 &&
@@ -168,8 +418,14 @@ func (r *Result) String() string {
 	if r.Missed != 0 {
 		value += fmt.Sprintf(", %d MISSED", r.Missed)
 	}
+	if r.Flaky != 0 {
+		value += fmt.Sprintf(", %d FLAKY", r.Flaky)
+	}
 	if r.WorkDir != "" {
 		value += "\nWORK=" + r.WorkDir
 	}
+	if r.ShuffleSeed != 0 {
+		value += fmt.Sprintf("\nSHUFFLE=%d", r.ShuffleSeed)
+	}
 	return value
 }
@@ -4,8 +4,12 @@ package check_test
 
 import (
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sync"
+	"time"
 
 	. "github.com/elopio/check"
 )
@@ -128,6 +132,49 @@ func (s *RunS) TestAdd(c *C) {
 	c.Check(result.RunError, IsNil)
 }
 
+func (s *RunS) TestAddTests(c *C) {
+	result := &Result{Tests: []TestResult{{Suite: "S", Method: "Test1"}}}
+	result.Add(&Result{Tests: []TestResult{{Suite: "T", Method: "Test2"}}})
+	result.Add(&Result{})
+	c.Check(result.Tests, DeepEquals, []TestResult{
+		{Suite: "S", Method: "Test1"},
+		{Suite: "T", Method: "Test2"},
+	})
+}
+
+// -----------------------------------------------------------------------
+// Check the per-test results.
+
+func (s *RunS) TestResultTests(c *C) {
+	output := String{}
+	helper := &FixtureHelper{panicOn: "Test1", sleepOn: "Test2", sleep: time.Millisecond}
+	result := Run(helper, &RunConf{Output: &output})
+	c.Assert(result.Tests, HasLen, 2)
+
+	test1 := result.Tests[0]
+	c.Check(test1.Name(), Equals, "FixtureHelper.Test1")
+	c.Check(test1.Status, Equals, EventPanic)
+	c.Check(test1.Log, Matches, "(?s)\\.\\.\\. Panic: Test1 .*")
+	c.Check(test1.Benchmark, IsNil)
+
+	test2 := result.Tests[1]
+	c.Check(test2.Name(), Equals, "FixtureHelper.Test2")
+	c.Check(test2.Status, Equals, EventPass)
+	c.Check(test2.Log, Equals, "")
+	c.Check(test2.Duration >= time.Millisecond, Equals, true)
+}
+
+func (s *RunS) TestResultTestsMissed(c *C) {
+	output := String{}
+	helper := &FixtureHelper{panicOn: "SetUpSuite"}
+	result := Run(helper, &RunConf{Output: &output})
+	c.Assert(result.Tests, HasLen, 2)
+	c.Check(result.Tests[0].Name(), Equals, "FixtureHelper.Test1")
+	c.Check(result.Tests[0].Status, Equals, EventMiss)
+	c.Check(result.Tests[1].Name(), Equals, "FixtureHelper.Test2")
+	c.Check(result.Tests[1].Status, Equals, EventMiss)
+}
+
 // -----------------------------------------------------------------------
 // Check the Passed() method.
 
@@ -188,6 +235,11 @@ func (s *RunS) TestPrintAll(c *C) {
 			"5 FIXTURE-PANICKED, 6 MISSED")
 }
 
+func (s *RunS) TestPrintShuffleSeed(c *C) {
+	result := &Result{Succeeded: 1, ShuffleSeed: 42}
+	c.Check(result.String(), Equals, "OK: 1 passed\nSHUFFLE=42")
+}
+
 func (s *RunS) TestPrintRunError(c *C) {
 	result := &Result{Succeeded: 1, Failed: 1,
 		RunError: errors.New("Kaboom!")}
@@ -299,6 +351,40 @@ func (s *RunS) TestList(c *C) {
 	})
 }
 
+// -----------------------------------------------------------------------
+// Verify that tests may be shuffled reproducibly.
+
+func (s *RunS) TestShuffle(c *C) {
+	unshuffled := List(&FixtureHelper{}, &RunConf{})
+	shuffled := false
+	for seed := int64(1); seed <= 20; seed++ {
+		runConf := &RunConf{Shuffle: true, ShuffleSeed: seed}
+		names := List(&FixtureHelper{}, runConf)
+		c.Assert(List(&FixtureHelper{}, runConf), DeepEquals, names)
+		c.Assert(names, HasLen, len(unshuffled))
+		c.Assert(names, DeepContains, unshuffled[0])
+		c.Assert(names, DeepContains, unshuffled[1])
+		if names[0] != unshuffled[0] {
+			shuffled = true
+		}
+	}
+	c.Assert(shuffled, Equals, true)
+}
+
+func (s *RunS) TestShuffleRun(c *C) {
+	output := String{}
+	helper := FixtureHelper{}
+	result := Run(&helper, &RunConf{Output: &output, Shuffle: true, ShuffleSeed: 7})
+	c.Check(result.Succeeded, Equals, 2)
+	c.Check(result.ShuffleSeed, Equals, int64(7))
+	c.Check(result.String(), Equals, "OK: 2 passed\nSHUFFLE=7")
+	c.Check(helper.calls[0], Equals, "SetUpSuite")
+	c.Check(helper.calls[len(helper.calls)-1], Equals, "TearDownSuite")
+
+	result = Run(&FixtureHelper{}, &RunConf{Output: &output, Shuffle: true})
+	c.Check(result.ShuffleSeed, Not(Equals), int64(0))
+}
+
 // -----------------------------------------------------------------------
 // Verify that verbose mode prints tests which pass as well.
 
@@ -418,3 +504,784 @@ func (s *RunS) TestKeepWorkDir(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(stat.IsDir(), Equals, true)
 }
+
+// -----------------------------------------------------------------------
+// Verify that tests calling Parallel run concurrently.
+
+type ParallelHelper struct {
+	calls   []string
+	m       sync.Mutex
+	started [2]chan bool
+}
+
+func (s *ParallelHelper) trace(name string) {
+	s.m.Lock()
+	s.calls = append(s.calls, name)
+	s.m.Unlock()
+}
+
+func (s *ParallelHelper) SetUpSuite(c *C) {
+	s.trace("SetUpSuite")
+	s.started = [2]chan bool{make(chan bool), make(chan bool)}
+}
+
+func (s *ParallelHelper) TearDownSuite(c *C) {
+	s.trace("TearDownSuite")
+}
+
+// Each test waits for the other one to start, so they may only pass
+// when running concurrently.
+func (s *ParallelHelper) rendezvous(c *C, mine, other int) {
+	c.Parallel()
+	close(s.started[mine])
+	select {
+	case <-s.started[other]:
+	case <-time.After(500 * time.Millisecond):
+		c.Fatal("other parallel test hasn't started")
+	}
+}
+
+func (s *ParallelHelper) Test1(c *C) {
+	s.rendezvous(c, 0, 1)
+	s.trace("Test1")
+}
+
+func (s *ParallelHelper) Test2(c *C) {
+	s.rendezvous(c, 1, 0)
+	s.trace("Test2")
+}
+
+func (s *ParallelHelper) Test3(c *C) {
+	s.trace("Test3")
+}
+
+func (s *RunS) TestParallel(c *C) {
+	helper := ParallelHelper{}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output, Parallelism: 2})
+	c.Check(result.Succeeded, Equals, 3)
+	c.Check(result.Failed, Equals, 0)
+	c.Assert(helper.calls, HasLen, 5)
+	c.Check(helper.calls[0], Equals, "SetUpSuite")
+	c.Check(helper.calls[1], Equals, "Test3") // Serial tests run first.
+	c.Check(helper.calls[4], Equals, "TearDownSuite")
+}
+
+func (s *RunS) TestParallelismLimit(c *C) {
+	helper := ParallelHelper{}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output, Parallelism: 1})
+	c.Check(result.Succeeded, Equals, 2)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(helper.calls[len(helper.calls)-1], Equals, "TearDownSuite")
+}
+
+type ParallelFixtureHelper struct{}
+
+func (s *ParallelFixtureHelper) SetUpTest(c *C) {
+	c.Parallel()
+}
+
+func (s *ParallelFixtureHelper) Test(c *C) {}
+
+func (s *RunS) TestParallelFromFixture(c *C) {
+	output := String{}
+	result := Run(&ParallelFixtureHelper{}, &RunConf{Output: &output})
+	c.Check(result.FixturePanicked, Equals, 1)
+	c.Check(result.Missed, Equals, 1)
+	c.Check(output.value, Matches, "(?s).*Panic: Parallel called from a fixture method.*")
+}
+
+// -----------------------------------------------------------------------
+// Verify that tests running past the timeout are failed and left behind.
+
+type TimeoutHelper struct {
+	block chan bool
+}
+
+func (s *TimeoutHelper) Test1(c *C) {
+	<-s.block
+}
+
+func (s *TimeoutHelper) Test2(c *C) {
+}
+
+func (s *RunS) TestTestTimeout(c *C) {
+	helper := TimeoutHelper{block: make(chan bool)}
+	defer close(helper.block)
+	output := String{}
+	runConf := RunConf{Output: &output, TestTimeout: 50 * time.Millisecond}
+	result := Run(&helper, &runConf)
+	c.Check(result.Succeeded, Equals, 1)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(output.value, Matches, "(?s)\n-+\n"+
+		"FAIL: run_test\\.go:[0-9]+: TimeoutHelper\\.Test1\n\n"+
+		"\\.\\.\\. Timeout: call exceeded 50ms, goroutines were:\n\n"+
+		"goroutine .*TimeoutHelper\\).Test1.*")
+}
+
+type TimeoutTearDownHelper struct {
+	calls []string
+	m     sync.Mutex
+	dir   string
+}
+
+func (s *TimeoutTearDownHelper) trace(name string) {
+	s.m.Lock()
+	s.calls = append(s.calls, name)
+	s.m.Unlock()
+}
+
+func (s *TimeoutTearDownHelper) TearDownTest(c *C) {
+	s.trace("TearDownTest")
+}
+
+func (s *TimeoutTearDownHelper) TearDownSuite(c *C) {
+	s.trace("TearDownSuite")
+}
+
+func (s *TimeoutTearDownHelper) Test1(c *C) {
+	s.dir = c.MkDir()
+	c.Cleanup(func() {
+		_, err := os.Stat(s.dir)
+		s.trace(fmt.Sprintf("Cleanup: %v", err))
+	})
+	time.Sleep(150 * time.Millisecond)
+	s.trace("Test1")
+}
+
+func (s *RunS) TestTestTimeoutWaitsForTearDown(c *C) {
+	helper := TimeoutTearDownHelper{}
+	output := String{}
+	runConf := RunConf{Output: &output, TestTimeout: 100 * time.Millisecond}
+	result := Run(&helper, &runConf)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(helper.calls, DeepEquals, []string{"Test1", "TearDownTest", "Cleanup: <nil>", "TearDownSuite"})
+	_, err := os.Stat(helper.dir)
+	c.Check(os.IsNotExist(err), Equals, true)
+}
+
+type SetUpSuiteTimeoutHelper struct{}
+
+func (s *SetUpSuiteTimeoutHelper) SetUpSuite(c *C) {
+	time.Sleep(100 * time.Millisecond)
+}
+
+func (s *SetUpSuiteTimeoutHelper) Test1(c *C) {
+}
+
+func (s *SetUpSuiteTimeoutHelper) Test2(c *C) {
+}
+
+func (s *RunS) TestTestTimeoutSkipsSetUpSuite(c *C) {
+	output := String{}
+	runConf := RunConf{Output: &output, TestTimeout: 50 * time.Millisecond}
+	result := Run(&SetUpSuiteTimeoutHelper{}, &runConf)
+	c.Check(result.Succeeded, Equals, 2)
+	c.Check(result.Failed, Equals, 0)
+	c.Check(result.Missed, Equals, 0)
+	c.Check(output.value, Equals, "")
+}
+
+type BenchmarkTimeoutHelper struct{}
+
+func (s *BenchmarkTimeoutHelper) Benchmark1(c *C) {
+	for i := 0; i < c.N; i++ {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (s *RunS) TestTestTimeoutSkipsBenchmarks(c *C) {
+	output := String{}
+	runConf := RunConf{
+		Output:        &output,
+		Benchmark:     true,
+		BenchmarkTime: 100 * time.Millisecond,
+		TestTimeout:   50 * time.Millisecond,
+	}
+	result := Run(&BenchmarkTimeoutHelper{}, &runConf)
+	c.Check(result.Succeeded, Equals, 1)
+	c.Check(result.Failed, Equals, 0)
+	c.Check(output.value, Not(Matches), "(?s).*Timeout: call exceeded.*")
+}
+
+// -----------------------------------------------------------------------
+// Verify that subtests are run, reported and counted on their own.
+
+type SubtestHelper struct {
+	ran     []string
+	results []bool
+}
+
+func (s *SubtestHelper) SetUpTest(c *C) {
+	s.ran = append(s.ran, "SetUpTest")
+}
+
+func (s *SubtestHelper) TestTable(c *C) {
+	for _, name := range []string{"pass", "fail", "skip me"} {
+		name := name
+		result := c.Run(name, func(c *C) {
+			s.ran = append(s.ran, c.TestName())
+			switch name {
+			case "fail":
+				c.Log("Expected failure!")
+				c.Fail()
+			case "skip me":
+				c.Skip("No reason")
+			}
+		})
+		s.results = append(s.results, result)
+	}
+}
+
+func (s *SubtestHelper) TestNested(c *C) {
+	c.Run("outer", func(c *C) {
+		c.Run("inner", func(c *C) {
+			s.ran = append(s.ran, c.TestName())
+		})
+	})
+}
+
+func (s *RunS) TestSubtests(c *C) {
+	helper := SubtestHelper{}
+	output := String{}
+	r := &eventRecorder{}
+	result := Run(&helper, &RunConf{Output: &output, Verbose: true, Reporters: []Reporter{r}})
+	c.Check(result.Succeeded, Equals, 4)
+	c.Check(result.Failed, Equals, 2)
+	c.Check(result.Skipped, Equals, 1)
+	c.Check(helper.ran, DeepEquals, []string{
+		"SetUpTest",
+		"SubtestHelper.TestNested/outer/inner",
+		"SetUpTest",
+		"SubtestHelper.TestTable/pass",
+		"SubtestHelper.TestTable/fail",
+		"SubtestHelper.TestTable/skip_me",
+	})
+	c.Check(helper.results, DeepEquals, []bool{true, false, true})
+
+	var names []string
+	for _, test := range result.Tests {
+		names = append(names, test.Status.String()+" "+test.Name())
+	}
+	c.Check(names, DeepEquals, []string{
+		"PASS SubtestHelper.TestNested/outer/inner",
+		"PASS SubtestHelper.TestNested/outer",
+		"PASS SubtestHelper.TestNested",
+		"PASS SubtestHelper.TestTable/pass",
+		"FAIL SubtestHelper.TestTable/fail",
+		"SKIP SubtestHelper.TestTable/skip_me",
+		"FAIL SubtestHelper.TestTable",
+	})
+
+	c.Check(output.value, Matches, "(?s).*"+
+		"PASS: run_test\\.go:[0-9]+: SubtestHelper\\.TestTable/pass\t *[.0-9]+s\n\n"+
+		"-+\n"+
+		"FAIL: run_test\\.go:[0-9]+: SubtestHelper\\.TestTable/fail\n\n"+
+		"Expected failure!\n\n"+
+		"-+\n"+
+		"SKIP: run_test\\.go:[0-9]+: SubtestHelper\\.TestTable/skip_me \\(No reason\\)\n.*")
+}
+
+func (s *RunS) TestSubtestsFilter(c *C) {
+	helper := SubtestHelper{}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output, Filter: "SubtestHelper.Test(Table|Nested)/(pass|outer)/x"})
+	c.Check(result.Succeeded, Equals, 4)
+	c.Check(result.Failed, Equals, 0)
+	c.Check(helper.ran, DeepEquals, []string{
+		"SetUpTest",
+		"SetUpTest",
+		"SubtestHelper.TestTable/pass",
+	})
+	// Filtered out subtests are not failures.
+	c.Check(helper.results, DeepEquals, []bool{true, true, true})
+}
+
+func (s *RunS) TestSplitFilter(c *C) {
+	output := String{}
+	result := Run(&SubtestHelper{}, &RunConf{Output: &output, Filter: "Test[/]Table/pa(s|/)s"})
+	c.Check(result.RunError, IsNil)
+	c.Check(result.Succeeded, Equals, 0)
+
+	result = Run(&SubtestHelper{}, &RunConf{Output: &output, Filter: "Table/pa(ss"})
+	c.Check(result.String(), Equals,
+		"ERROR: Bad filter expression: error parsing regexp: missing closing ): `pa(ss`")
+}
+
+// -----------------------------------------------------------------------
+// Verify that fail-fast mode stops running tests after the first failure.
+
+type FailFastHelper struct {
+	calls []string
+}
+
+func (s *FailFastHelper) TearDownSuite(c *C) {
+	s.calls = append(s.calls, "TearDownSuite")
+}
+
+func (s *FailFastHelper) Test1(c *C) {
+	s.calls = append(s.calls, "Test1")
+}
+
+func (s *FailFastHelper) Test2(c *C) {
+	s.calls = append(s.calls, "Test2")
+	c.Fail()
+}
+
+func (s *FailFastHelper) Test3(c *C) {
+	s.calls = append(s.calls, "Test3")
+}
+
+func (s *RunS) TestFailFast(c *C) {
+	helper := FailFastHelper{}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output, Verbose: true, FailFast: true})
+	c.Check(result.Succeeded, Equals, 1)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(result.Missed, Equals, 1)
+	c.Check(helper.calls, DeepEquals, []string{"Test1", "Test2", "TearDownSuite"})
+	c.Check(output.value, Matches,
+		"(?s).*\nMISS: run_test\\.go:[0-9]+: FailFastHelper\\.Test3 \\(not run\\)\n")
+}
+
+func (s *RunS) TestFailFastDisabled(c *C) {
+	helper := FailFastHelper{}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output})
+	c.Check(result.Succeeded, Equals, 2)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(helper.calls, DeepEquals, []string{"Test1", "Test2", "Test3", "TearDownSuite"})
+}
+
+type FailFastParallelHelper struct {
+	ran       []string
+	tearDowns int
+	m         sync.Mutex
+}
+
+func (s *FailFastParallelHelper) TearDownTest(c *C) {
+	s.m.Lock()
+	s.tearDowns++
+	s.m.Unlock()
+}
+
+func (s *FailFastParallelHelper) fail(c *C, name string) {
+	c.Parallel()
+	s.m.Lock()
+	s.ran = append(s.ran, name)
+	s.m.Unlock()
+	c.Fail()
+}
+
+func (s *FailFastParallelHelper) Test1(c *C) {
+	s.fail(c, "Test1")
+}
+
+func (s *FailFastParallelHelper) Test2(c *C) {
+	s.fail(c, "Test2")
+}
+
+func (s *FailFastParallelHelper) Test3(c *C) {
+	s.fail(c, "Test3")
+}
+
+func (s *RunS) TestFailFastStopsParallelTests(c *C) {
+	helper := FailFastParallelHelper{}
+	output := String{}
+	runConf := RunConf{Output: &output, Verbose: true, FailFast: true, Parallelism: 1}
+	result := Run(&helper, &runConf)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(result.Missed, Equals, 2)
+	c.Check(helper.ran, HasLen, 1)
+	c.Check(helper.tearDowns, Equals, 3)
+	c.Check(output.value, Matches, "(?s)(.*\nMISS: run_test\\.go:[0-9]+: FailFastParallelHelper\\.Test[1-3] \\(not run\\)){2}\n")
+}
+
+func (s *RunS) TestFailFastSkipsLaterSuites(c *C) {
+	helper := FixtureHelper{}
+	output := String{}
+	suites := []interface{}{&SuccessHelper{}, &FailHelper{}, &helper}
+	result := RunAllOf(suites, &RunConf{Output: &output, FailFast: true})
+	c.Check(result.Succeeded, Equals, 1)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(result.Missed, Equals, 2)
+	c.Check(helper.calls, IsNil)
+	c.Check(result.Tests[2].Status, Equals, EventMiss)
+	c.Check(result.Tests[2].Reason, Equals, "not run")
+}
+
+// -----------------------------------------------------------------------
+// Verify that tests may be run several times, and retried when failing.
+
+type CountHelper struct {
+	calls []string
+	runs  map[string]int
+	fails map[string]int // Number of runs each test fails before passing.
+}
+
+func (s *CountHelper) SetUpTest(c *C) {
+	s.calls = append(s.calls, "SetUpTest")
+}
+
+func (s *CountHelper) TearDownTest(c *C) {
+	s.calls = append(s.calls, "TearDownTest")
+}
+
+func (s *CountHelper) run(c *C, name string) {
+	s.calls = append(s.calls, name)
+	s.runs[name]++
+	if s.runs[name] <= s.fails[name] {
+		c.Fatalf("Run %d failed", s.runs[name])
+	}
+}
+
+func (s *CountHelper) Test1(c *C) {
+	s.run(c, "Test1")
+}
+
+func (s *CountHelper) Test2(c *C) {
+	s.run(c, "Test2")
+}
+
+func (s *RunS) TestCount(c *C) {
+	helper := CountHelper{runs: map[string]int{}, fails: map[string]int{"Test2": 1}}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output, Count: 3})
+	c.Check(result.Succeeded, Equals, 5)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(result.Tests, HasLen, 6)
+	c.Check(helper.calls[:6], DeepEquals, []string{
+		"SetUpTest", "Test1", "TearDownTest",
+		"SetUpTest", "Test1", "TearDownTest",
+	})
+	c.Check(len(helper.calls), Equals, 18)
+	c.Check(output.value, Matches, "(?s).*\nRATIO: run_test\\.go:[0-9]+: CountHelper\\.Test2\t2/3 passed\n$")
+	c.Check(output.value, Not(Matches), "(?s).*CountHelper\\.Test1\t.*")
+
+	output = String{}
+	helper.runs = map[string]int{}
+	Run(&helper, &RunConf{Output: &output, Count: 3, Verbose: true})
+	c.Check(output.value, Matches, "(?s).*\n"+
+		"RATIO: run_test\\.go:[0-9]+: CountHelper\\.Test1\t3/3 passed\n"+
+		"RATIO: run_test\\.go:[0-9]+: CountHelper\\.Test2\t2/3 passed\n$")
+}
+
+func (s *RunS) TestRetry(c *C) {
+	helper := CountHelper{runs: map[string]int{}, fails: map[string]int{"Test1": 2, "Test2": 5}}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output, Verbose: true, Retry: 2})
+	c.Check(result.Succeeded, Equals, 0)
+	c.Check(result.Flaky, Equals, 1)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(helper.runs, DeepEquals, map[string]int{"Test1": 3, "Test2": 3})
+	c.Check(result.String(), Equals, "OOPS: 0 passed, 1 FAILED, 1 FLAKY")
+
+	var retried []bool
+	for _, test := range result.Tests {
+		retried = append(retried, test.Retried)
+	}
+	c.Check(retried, DeepEquals, []bool{true, true, false, true, true, false})
+
+	// Every failed attempt is still shown.
+	c.Check(output.value, Matches, "(?s).*FAIL: run_test\\.go:[0-9]+: CountHelper\\.Test1\n\n"+
+		"run_test\\.go:[0-9]+:\n.*\\.\\.\\. Error: Run 2 failed\n.*"+
+		"FLAKY: run_test\\.go:[0-9]+: CountHelper\\.Test1\t *[.0-9]+s\n.*")
+
+	helper = CountHelper{runs: map[string]int{}, fails: map[string]int{"Test1": 1}}
+	result = Run(&helper, &RunConf{Output: &output, Retry: 1})
+	c.Check(result.Passed(), Equals, true)
+	c.Check(result.String(), Equals, "OK: 1 passed, 1 FLAKY")
+}
+
+type FlakyHelper struct {
+	runs int
+}
+
+// Fails on every first attempt.
+func (s *FlakyHelper) TestFlaky(c *C) {
+	s.runs++
+	if s.runs%2 == 1 {
+		c.Fail()
+	}
+}
+
+func (s *RunS) TestCountWithRetry(c *C) {
+	output := String{}
+	result := Run(&FlakyHelper{}, &RunConf{Output: &output, Count: 3, Retry: 1})
+	c.Check(result.String(), Equals, "OK: 0 passed, 3 FLAKY")
+	c.Check(output.value, Matches, "(?s).*\nRATIO: run_test\\.go:[0-9]+: FlakyHelper\\.TestFlaky\t0/3 passed, 3 flaky\n$")
+}
+
+type ParallelRetryHelper struct {
+	runs int
+}
+
+func (s *ParallelRetryHelper) TestFlaky(c *C) {
+	c.Parallel()
+	s.runs++
+	if s.runs == 1 {
+		c.Fail()
+	}
+}
+
+func (s *RunS) TestRetryNotParallel(c *C) {
+	helper := ParallelRetryHelper{}
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output, Retry: 2})
+	c.Check(result.String(), Equals, "OOPS: 0 passed, 1 FAILED")
+	c.Check(helper.runs, Equals, 1)
+	c.Check(result.Tests, HasLen, 1)
+	c.Check(result.Tests[0].Retried, Equals, false)
+}
+
+type SubtestRetryHelper struct {
+	runs int
+}
+
+func (s *SubtestRetryHelper) TestParent(c *C) {
+	s.runs++
+	c.Run("sub", func(c *C) {
+		if s.runs == 1 {
+			c.Fail()
+		}
+	})
+}
+
+func (s *RunS) TestRetrySubtests(c *C) {
+	output := String{}
+	result := Run(&SubtestRetryHelper{}, &RunConf{Output: &output, Retry: 1})
+	c.Check(result.String(), Equals, "OK: 1 passed, 1 FLAKY")
+	c.Check(result.Passed(), Equals, true)
+
+	var names []string
+	var retried []bool
+	for _, test := range result.Tests {
+		names = append(names, test.Name())
+		retried = append(retried, test.Retried)
+	}
+	c.Check(names, DeepEquals, []string{
+		"SubtestRetryHelper.TestParent/sub", "SubtestRetryHelper.TestParent",
+		"SubtestRetryHelper.TestParent/sub", "SubtestRetryHelper.TestParent",
+	})
+	c.Check(retried, DeepEquals, []bool{true, true, false, false})
+}
+
+// -----------------------------------------------------------------------
+// Verify the selection of tests by exclusion and by tags.
+
+type TaggedHelper struct{}
+
+func (s *TaggedHelper) Tags() map[string][]string {
+	return map[string][]string{
+		"":          {"unit"},
+		"TestDB":    {"integration", "db"},
+		"TestSlow":  {"integration", "slow"},
+		"TestOther": {"other-tag"},
+	}
+}
+
+func (s *TaggedHelper) TestDB(c *C)    {}
+func (s *TaggedHelper) TestSlow(c *C)  {}
+func (s *TaggedHelper) TestOther(c *C) {}
+func (s *TaggedHelper) TestPlain(c *C) {}
+
+func (s *RunS) TestExclude(c *C) {
+	c.Check(List(&TaggedHelper{}, &RunConf{Exclude: "Slow|Other"}), DeepEquals, []string{
+		"TaggedHelper.TestDB",
+		"TaggedHelper.TestPlain",
+	})
+	c.Check(List(&TaggedHelper{}, &RunConf{Filter: "TestDB|TestSlow", Exclude: "TestSlow"}), DeepEquals, []string{
+		"TaggedHelper.TestDB",
+	})
+
+	output := String{}
+	result := Run(&TaggedHelper{}, &RunConf{Output: &output, Exclude: "TaggedHelper"})
+	c.Check(result.Succeeded, Equals, 0)
+
+	result = Run(&TaggedHelper{}, &RunConf{Output: &output, Exclude: "("})
+	c.Check(result.String(), Equals, "ERROR: Bad exclude expression: error parsing regexp: missing closing ): `(`")
+}
+
+func (s *RunS) TestTags(c *C) {
+	tests := []struct {
+		tags     string
+		expected []string
+	}{
+		{"integration", []string{"TestDB", "TestSlow"}},
+		{"integration && !slow", []string{"TestDB"}},
+		{"!integration", []string{"TestOther", "TestPlain"}},
+		{"db || other-tag", []string{"TestDB", "TestOther"}},
+		{"unit && !(slow || db)", []string{"TestOther", "TestPlain"}},
+		{"!!slow", []string{"TestSlow"}},
+		{"slow || db && !integration", []string{"TestSlow"}},
+		{"missing", nil},
+	}
+	for _, test := range tests {
+		var expected []string
+		for _, name := range test.expected {
+			expected = append(expected, "TaggedHelper."+name)
+		}
+		c.Check(List(&TaggedHelper{}, &RunConf{Tags: test.tags}), DeepEquals, expected,
+			Commentf("Tags: %q", test.tags))
+	}
+
+	output := String{}
+	result := Run(&TaggedHelper{}, &RunConf{Output: &output, Tags: "integration"})
+	c.Check(result.Succeeded, Equals, 2)
+
+	// Suites without tags have none of them.
+	result = Run(&SuccessHelper{}, &RunConf{Output: &output, Tags: "!integration"})
+	c.Check(result.Succeeded, Equals, 1)
+}
+
+func (s *RunS) TestBadTags(c *C) {
+	tests := []struct {
+		tags     string
+		expected string
+	}{
+		{" ", `Bad tags expression " ": missing tag`},
+		{"a &&", `Bad tags expression "a &&": missing tag`},
+		{"a b", `Bad tags expression "a b": unexpected "b"`},
+		{"(a || b", `Bad tags expression "\(a \|\| b": missing \)`},
+		{"a & b", `Bad tags expression "a & b": unexpected "&"`},
+		{"a) || (b", `Bad tags expression "a\) \|\| \(b": unexpected "\)"`},
+		{"|| a", `Bad tags expression "\|\| a": unexpected "\|\|"`},
+	}
+	for _, test := range tests {
+		output := String{}
+		result := Run(&TaggedHelper{}, &RunConf{Output: &output, Tags: test.tags})
+		c.Check(result.RunError, ErrorMatches, test.expected, Commentf("Tags: %q", test.tags))
+	}
+}
+
+// -----------------------------------------------------------------------
+// Verify the record of failed tests, for running just those again.
+
+func (s *RunS) TestFailedTests(c *C) {
+	output := String{}
+	result := Run(&FixtureHelper{panicOn: "Test1"}, &RunConf{Output: &output})
+	c.Check(result.FailedTests(), DeepEquals, []string{"FixtureHelper.Test1"})
+
+	result = Run(&FixtureHelper{panicOn: "SetUpSuite"}, &RunConf{Output: &output})
+	c.Check(result.FailedTests(), DeepEquals, []string{"FixtureHelper.Test1", "FixtureHelper.Test2"})
+
+	helper := CountHelper{runs: map[string]int{}, fails: map[string]int{"Test1": 1, "Test2": 2}}
+	result = Run(&helper, &RunConf{Output: &output, Retry: 1})
+	c.Check(result.FailedTests(), DeepEquals, []string{"CountHelper.Test2"})
+
+	result = Run(&SubtestHelper{}, &RunConf{Output: &output})
+	c.Check(result.FailedTests(), DeepEquals, []string{"SubtestHelper.TestTable"})
+}
+
+func (s *RunS) TestRecordFailedTests(c *C) {
+	path := filepath.Join(c.MkDir(), "state")
+	names, err := ReadFailedTests(path)
+	c.Assert(err, IsNil)
+	c.Assert(names, IsNil)
+
+	output := String{}
+	result := Run(&JUnitHelper{}, &RunConf{Output: &output})
+	c.Assert(WriteFailedTests(path, result), IsNil)
+	names, err = ReadFailedTests(path)
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"JUnitHelper.TestFail", "JUnitHelper.TestPanic"})
+
+	// Rerunning selects exactly the failed tests, and a test which now
+	// passes is forgotten while the ones not run are kept.
+	conf := &RunConf{Output: &output, Filter: FailedFilter(names)}
+	c.Check(List(&JUnitHelper{}, conf), DeepEquals, names)
+	c.Check(List(&FailHelper{}, conf), IsNil)
+	result = &Result{Tests: []TestResult{
+		{Suite: "JUnitHelper", Method: "TestFail", Status: EventPass},
+		{Suite: "FailHelper", Method: "TestLogAndFail", Status: EventFail},
+	}}
+	c.Assert(WriteFailedTests(path, result), IsNil)
+	names, err = ReadFailedTests(path)
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"FailHelper.TestLogAndFail", "JUnitHelper.TestPanic"})
+
+	// With nothing recorded, nothing is selected.
+	c.Check(List(&JUnitHelper{}, &RunConf{Filter: FailedFilter(nil)}), IsNil)
+}
+
+func (s *RunS) TestRecordFailedTestsReplacesFile(c *C) {
+	dir := c.MkDir()
+	target := filepath.Join(dir, "target")
+	c.Assert(ioutil.WriteFile(target, []byte("S.TestOld\n"), 0644), IsNil)
+	link := filepath.Join(dir, "link")
+	c.Assert(os.Symlink(target, link), IsNil)
+
+	// Links are replaced rather than written through, though what they
+	// pointed to is still merged in.
+	result := &Result{Tests: []TestResult{{Suite: "S", Method: "TestA", Status: EventFail}}}
+	c.Assert(WriteFailedTests(link, result), IsNil)
+	data, err := ioutil.ReadFile(target)
+	c.Assert(err, IsNil)
+	c.Check(string(data), Equals, "S.TestOld\n")
+	names, err := ReadFailedTests(link)
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"S.TestA", "S.TestOld"})
+
+	// Missing directories are created.
+	path := filepath.Join(dir, "sub", "state")
+	c.Assert(WriteFailedTests(path, result), IsNil)
+	names, err = ReadFailedTests(path)
+	c.Assert(err, IsNil)
+	c.Check(names, DeepEquals, []string{"S.TestA"})
+}
+
+// -----------------------------------------------------------------------
+// Verify the detection of goroutines leaked by tests.
+
+type LeakHelper struct {
+	block chan bool
+}
+
+func (s *LeakHelper) TestLeak(c *C) {
+	go func() { <-s.block }()
+}
+
+func (s *LeakHelper) TestStopped(c *C) {
+	done := make(chan bool)
+	go func() { <-done }()
+	c.Cleanup(func() { close(done) })
+}
+
+func (s *LeakHelper) TestStopping(c *C) {
+	go time.Sleep(50 * time.Millisecond)
+}
+
+func (s *LeakHelper) TestAllowed(c *C) {
+	go allowedLeak(s.block)
+}
+
+func allowedLeak(block chan bool) {
+	<-block
+}
+
+func (s *RunS) TestDetectLeaks(c *C) {
+	helper := LeakHelper{block: make(chan bool)}
+	defer close(helper.block)
+	output := String{}
+	result := Run(&helper, &RunConf{
+		Output:        &output,
+		DetectLeaks:   true,
+		LeakGrace:     200 * time.Millisecond,
+		LeakAllowlist: []string{"check_test.allowedLeak"},
+	})
+	c.Check(result.Succeeded, Equals, 3)
+	c.Check(result.Failed, Equals, 1)
+	c.Check(output.value, Matches, "(?s)\\n-+\\n"+
+		"FAIL: run_test\\.go:[0-9]+: LeakHelper\\.TestLeak\n\n"+
+		"\\.\\.\\. Leak: 1 goroutine\\(s\\) started by the test still running:\n\n"+
+		"goroutine [0-9]+ \\[chan receive\\]:\n.*\\(\\*LeakHelper\\)\\.TestLeak.*")
+}
+
+func (s *RunS) TestLeaksNotDetectedByDefault(c *C) {
+	helper := LeakHelper{block: make(chan bool)}
+	defer close(helper.block)
+	output := String{}
+	result := Run(&helper, &RunConf{Output: &output})
+	c.Check(result.Succeeded, Equals, 4)
+}
@@ -0,0 +1,131 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------
+// Splitting of the tests run across several processes.
+
+// Build the runners for the given suites, keeping only the tests owned
+// by the shard selected in the run configuration, if any.
+func newSuiteRunners(suites []interface{}, runConf *RunConf) []*suiteRunner {
+	var runners []*suiteRunner
+	var names []string
+	for _, suite := range suites {
+		runner := newSuiteRunner(suite, runConf)
+		runners = append(runners, runner)
+		for _, method := range runner.tests {
+			names = append(names, method.String())
+		}
+	}
+	if runConf == nil || runConf.Shards == 0 {
+		return runners
+	}
+	if runConf.Shard < 1 || runConf.Shard > runConf.Shards {
+		err := fmt.Errorf("Bad shard %d/%d: must be between 1 and %d",
+			runConf.Shard, runConf.Shards, runConf.Shards)
+		for _, runner := range runners {
+			runner.tracker.result.RunError = err
+			runner.tests = nil
+		}
+		return runners
+	}
+	owned := shardTests(names, runConf.Shard, runConf.Shards, runConf.ShardTimings)
+	for _, runner := range runners {
+		var tests []*methodType
+		for _, method := range runner.tests {
+			if owned[method.String()] {
+				tests = append(tests, method)
+			}
+		}
+		runner.tests = tests
+	}
+	return runners
+}
+
+// Return the names of the tests owned by the given shard, counting from
+// one. Without timings, tests are assigned by a stable hash of their
+// names. Otherwise, they're spread so that all shards take about the
+// same time to run, with tests that have no timing taking the average.
+func shardTests(names []string, shard, shards int, timings map[string]time.Duration) map[string]bool {
+	owned := make(map[string]bool)
+	if len(timings) == 0 {
+		for _, name := range names {
+			h := fnv.New32a()
+			h.Write([]byte(name))
+			if int(h.Sum32()%uint32(shards)) == shard-1 {
+				owned[name] = true
+			}
+		}
+		return owned
+	}
+
+	var known, total time.Duration
+	for _, name := range names {
+		if d, ok := timings[name]; ok {
+			known++
+			total += d
+		}
+	}
+	average := time.Duration(1)
+	if known > 0 && total > 0 {
+		average = total / known
+	}
+	type shardTest struct {
+		name     string
+		duration time.Duration
+	}
+	tests := make([]shardTest, len(names))
+	for i, name := range names {
+		d, ok := timings[name]
+		if !ok {
+			d = average
+		}
+		tests[i] = shardTest{name, d}
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		if tests[i].duration != tests[j].duration {
+			return tests[i].duration > tests[j].duration
+		}
+		return tests[i].name < tests[j].name
+	})
+	loads := make([]time.Duration, shards)
+	for _, test := range tests {
+		least := 0
+		for i := range loads {
+			if loads[i] < loads[least] {
+				least = i
+			}
+		}
+		loads[least] += test.duration
+		if least == shard-1 {
+			owned[test.name] = true
+		}
+	}
+	return owned
+}
+
+// ReadShardTimings reads the time each test took from the events written
+// by a previous run with -check.json, to be used as RunConf.ShardTimings.
+func ReadShardTimings(r io.Reader) (map[string]time.Duration, error) {
+	timings := make(map[string]time.Duration)
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var e jsonEvent
+		if err := decoder.Decode(&e); err != nil {
+			return nil, fmt.Errorf("Can't read shard timings: %v", err)
+		}
+		if e.Elapsed == nil || e.Test == "" || strings.Contains(e.Test, "/") {
+			continue
+		}
+		timings[e.Test] = time.Duration(*e.Elapsed * float64(time.Second))
+	}
+	return timings, nil
+}
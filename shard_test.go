@@ -0,0 +1,101 @@
+package check_test
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/elopio/check"
+)
+
+var _ = Suite(&shardS{})
+
+type shardS struct{}
+
+var shardSuites = []interface{}{
+	&FixtureHelper{},
+	&CountHelper{},
+	&JUnitHelper{},
+	&SubtestHelper{},
+	&FailFastHelper{},
+}
+
+func (s *shardS) TestShardsPartitionTests(c *C) {
+	all := ListAllOf(shardSuites, nil)
+	var names []string
+	for shard := 1; shard <= 3; shard++ {
+		owned := ListAllOf(shardSuites, &RunConf{Shard: shard, Shards: 3})
+		c.Check(len(owned) < len(all), Equals, true)
+		c.Check(ListAllOf(shardSuites, &RunConf{Shard: shard, Shards: 3}), DeepEquals, owned)
+		names = append(names, owned...)
+	}
+	sort.Strings(all)
+	sort.Strings(names)
+	c.Check(names, DeepEquals, all)
+}
+
+func (s *shardS) TestShardTimings(c *C) {
+	timings := map[string]time.Duration{
+		"JUnitHelper.TestFail":    4 * time.Second,
+		"JUnitHelper.TestPanic":   3 * time.Second,
+		"JUnitHelper.TestSkip":    2 * time.Second,
+		"JUnitHelper.TestSucceed": 1 * time.Second,
+	}
+	var shards []string
+	for shard := 1; shard <= 2; shard++ {
+		conf := &RunConf{Shard: shard, Shards: 2, ShardTimings: timings}
+		shards = append(shards, strings.Join(List(&JUnitHelper{}, conf), " "))
+	}
+	c.Check(shards, DeepEquals, []string{
+		"JUnitHelper.TestFail JUnitHelper.TestSucceed",
+		"JUnitHelper.TestPanic JUnitHelper.TestSkip",
+	})
+}
+
+func (s *shardS) TestFixturesOnlyRunInOwningShards(c *C) {
+	timings := map[string]time.Duration{
+		"FixtureHelper.Test1": 2 * time.Second,
+		"FixtureHelper.Test2": 1 * time.Second,
+	}
+	var calls [][]string
+	for shard := 1; shard <= 3; shard++ {
+		helper := FixtureHelper{}
+		output := String{}
+		Run(&helper, &RunConf{Output: &output, Shard: shard, Shards: 3, ShardTimings: timings})
+		calls = append(calls, helper.calls)
+	}
+	c.Check(calls, DeepEquals, [][]string{
+		{"SetUpSuite", "SetUpTest", "Test1", "TearDownTest", "TearDownSuite"},
+		{"SetUpSuite", "SetUpTest", "Test2", "TearDownTest", "TearDownSuite"},
+		nil,
+	})
+}
+
+func (s *shardS) TestBadShard(c *C) {
+	output := String{}
+	result := Run(&FixtureHelper{}, &RunConf{Output: &output, Shard: 3, Shards: 2})
+	c.Check(result.String(), Equals, "ERROR: Bad shard 3/2: must be between 1 and 2")
+	c.Check(List(&FixtureHelper{}, &RunConf{Shard: 0, Shards: 2}), IsNil)
+}
+
+func (s *shardS) TestReadShardTimings(c *C) {
+	output := String{}
+	stream := String{}
+	Run(&JUnitHelper{}, &RunConf{Output: &output, JSONOutput: &stream})
+	timings, err := ReadShardTimings(strings.NewReader(stream.value))
+	c.Assert(err, IsNil)
+	var names []string
+	for name := range timings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	c.Check(names, DeepEquals, []string{
+		"JUnitHelper.TestFail",
+		"JUnitHelper.TestPanic",
+		"JUnitHelper.TestSkip",
+		"JUnitHelper.TestSucceed",
+	})
+
+	_, err = ReadShardTimings(strings.NewReader("{"))
+	c.Check(err, ErrorMatches, "Can't read shard timings: .*")
+}
@@ -0,0 +1,144 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// -----------------------------------------------------------------------
+// Selection of tests by their tags.
+
+// Tagger is implemented by suites which tag their tests, so that they
+// may be selected with a tags expression in RunConf.Tags. For example:
+//
+//     func (s *MySuite) Tags() map[string][]string {
+//         return map[string][]string{
+//             "":         {"integration"},
+//             "TestSlow": {"slow"},
+//         }
+//     }
+//
+// The tags under an empty method name apply to all tests in the suite.
+type Tagger interface {
+	Tags() map[string][]string
+}
+
+// A tags expression, reporting whether a test with the given tags is
+// selected.
+type tagsExpr func(tags map[string]bool) bool
+
+// Return the tags of the given test method in the suite, if any.
+func methodTags(suite interface{}, method *methodType) map[string]bool {
+	tagger, ok := suite.(Tagger)
+	if !ok {
+		return nil
+	}
+	tags := make(map[string]bool)
+	all := tagger.Tags()
+	for _, name := range []string{"", method.Info.Name} {
+		for _, tag := range all[name] {
+			tags[tag] = true
+		}
+	}
+	return tags
+}
+
+// Parse a boolean expression on tags, such as "integration && !slow".
+// Tags may be combined with && (and), || (or), ! (not) and parenthesis,
+// with ! taking precedence over &&, and && over ||.
+func parseTags(expr string) (tagsExpr, error) {
+	p := &tagsParser{input: expr}
+	p.next()
+	e, err := p.parseOr()
+	if err == nil && p.token != "" {
+		err = fmt.Errorf("unexpected %q", p.token)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Bad tags expression %q: %v", expr, err)
+	}
+	return e, nil
+}
+
+type tagsParser struct {
+	input string
+	token string // Empty once the input is over.
+}
+
+// Move on to the next token: a tag, an operator, or a parenthesis.
+func (p *tagsParser) next() {
+	p.input = strings.TrimLeftFunc(p.input, unicode.IsSpace)
+	switch {
+	case p.input == "":
+		p.token = ""
+	case strings.HasPrefix(p.input, "&&"), strings.HasPrefix(p.input, "||"):
+		p.token = p.input[:2]
+	case strings.ContainsRune("!()", rune(p.input[0])):
+		p.token = p.input[:1]
+	default:
+		end := strings.IndexFunc(p.input, func(r rune) bool {
+			return unicode.IsSpace(r) || strings.ContainsRune("&|!()", r)
+		})
+		if end == -1 {
+			end = len(p.input)
+		} else if end == 0 {
+			end = 1
+		}
+		p.token = p.input[:end]
+	}
+	p.input = p.input[len(p.token):]
+}
+
+func (p *tagsParser) parseOr() (tagsExpr, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.token == "||" {
+		p.next()
+		var right tagsExpr
+		right, err = p.parseAnd()
+		l := left
+		left = func(tags map[string]bool) bool { return l(tags) || right(tags) }
+	}
+	return left, err
+}
+
+func (p *tagsParser) parseAnd() (tagsExpr, error) {
+	left, err := p.parseNot()
+	for err == nil && p.token == "&&" {
+		p.next()
+		var right tagsExpr
+		right, err = p.parseNot()
+		l := left
+		left = func(tags map[string]bool) bool { return l(tags) && right(tags) }
+	}
+	return left, err
+}
+
+func (p *tagsParser) parseNot() (tagsExpr, error) {
+	switch p.token {
+	case "!":
+		p.next()
+		e, err := p.parseNot()
+		return func(tags map[string]bool) bool { return !e(tags) }, err
+	case "(":
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.token != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.next()
+		return e, nil
+	case "":
+		return nil, fmt.Errorf("missing tag")
+	case "&&", "||", ")":
+		return nil, fmt.Errorf("unexpected %q", p.token)
+	}
+	if strings.ContainsAny(p.token, "&|") {
+		return nil, fmt.Errorf("unexpected %q", p.token)
+	}
+	tag := p.token
+	p.next()
+	return func(tags map[string]bool) bool { return tags[tag] }, nil
+}